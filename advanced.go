@@ -0,0 +1,172 @@
+package gtrans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/translate"
+	translatev3 "cloud.google.com/go/translate/apiv3"
+	"cloud.google.com/go/translate/apiv3/translatepb"
+	"golang.org/x/text/language"
+	"google.golang.org/api/option"
+)
+
+// DefaultLocation is the default location of Cloud Translation Advanced
+// API. Glossaries are not available in the global location.
+const DefaultLocation = "us-central1"
+
+// advancedClient is a Translator using Cloud Translation Advanced (v3) API.
+type advancedClient struct {
+	client   *translatev3.TranslationClient
+	parent   string
+	glossary string
+}
+
+// NewAdvancedClient returns a Translator using Cloud Translation Advanced
+// (v3) API of project in location (DefaultLocation if empty). It uses the
+// service account credentials file specified by
+// $GOOGLE_APPLICATION_CREDENTIALS or Application Default Credentials since
+// the API doesn't accept API keys.
+//
+// If glossary is not empty, translations use the glossary. It's a glossary
+// ID in the project and location or a full resource name such as
+// projects/<project>/locations/<location>/glossaries/<id>, whose project
+// and location are used if they are empty.
+func NewAdvancedClient(ctx context.Context, project, location, glossary string, opts ...option.ClientOption) (Translator, error) {
+	if strings.HasPrefix(glossary, "projects/") {
+		// projects/<project>/locations/<location>/glossaries/<id>
+		parts := strings.Split(glossary, "/")
+		if len(parts) != 6 || parts[2] != "locations" || parts[4] != "glossaries" {
+			return nil, fmt.Errorf("invalid glossary %q: must be an ID or projects/<project>/locations/<location>/glossaries/<id>", glossary)
+		}
+		if project == "" {
+			project = parts[1]
+		}
+		if location == "" {
+			location = parts[3]
+		}
+	}
+	if project == "" {
+		return nil, errors.New("Google Cloud project is required for Cloud Translation Advanced API")
+	}
+	if location == "" {
+		location = DefaultLocation
+	}
+	parent := fmt.Sprintf("projects/%s/locations/%s", project, location)
+	if glossary != "" && !strings.HasPrefix(glossary, "projects/") {
+		glossary = parent + "/glossaries/" + glossary
+	}
+	if file := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); file != "" {
+		opts = append([]option.ClientOption{option.WithCredentialsFile(file)}, opts...)
+	}
+	if UserAgent != "" {
+		opts = append([]option.ClientOption{option.WithUserAgent(UserAgent)}, opts...)
+	}
+	client, err := translatev3.NewTranslationClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Google Cloud credentials are not available: %v", err)
+	}
+	return &advancedClient{client: client, parent: parent, glossary: glossary}, nil
+}
+
+func (c *advancedClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	req := &translatepb.TranslateTextRequest{
+		Parent:             c.parent,
+		Contents:           inputs,
+		TargetLanguageCode: target.String(),
+		MimeType:           "text/plain",
+	}
+	if opts != nil {
+		if opts.Source != language.Und {
+			req.SourceLanguageCode = opts.Source.String()
+		}
+		if opts.Format == translate.HTML {
+			req.MimeType = "text/html"
+		}
+		if opts.Model != "" {
+			req.Model = c.parent + "/models/general/" + opts.Model
+		}
+	}
+	if c.glossary != "" {
+		req.GlossaryConfig = &translatepb.TranslateTextGlossaryConfig{Glossary: c.glossary}
+		if req.SourceLanguageCode == "" {
+			// Glossaries require the source language.
+			detections, err := c.DetectLanguage(ctx, []string{strings.Join(inputs, "\n")})
+			if err != nil {
+				return nil, err
+			}
+			if len(detections[0]) == 0 {
+				return nil, errors.New("failed to detect source language for glossary")
+			}
+			req.SourceLanguageCode = detections[0][0].Language.String()
+		}
+	}
+	resp, err := c.client.TranslateText(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ts := resp.GetTranslations()
+	if gts := resp.GetGlossaryTranslations(); len(gts) > 0 {
+		ts = gts
+	}
+	translations := make([]translate.Translation, len(ts))
+	for i, t := range ts {
+		translations[i] = translate.Translation{Text: t.GetTranslatedText(), Model: t.GetModel()}
+		code := t.GetDetectedLanguageCode()
+		if code == "" {
+			code = req.SourceLanguageCode
+		}
+		if tag, err := language.Parse(code); err == nil {
+			translations[i].Source = tag
+		}
+	}
+	return translations, nil
+}
+
+func (c *advancedClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	// DetectLanguage of v3 accepts only one text per request.
+	detectionsList := make([][]translate.Detection, len(inputs))
+	for i, input := range inputs {
+		resp, err := c.client.DetectLanguage(ctx, &translatepb.DetectLanguageRequest{
+			Parent: c.parent,
+			Source: &translatepb.DetectLanguageRequest_Content{Content: input},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range resp.GetLanguages() {
+			tag, err := language.Parse(l.GetLanguageCode())
+			if err != nil {
+				continue
+			}
+			detectionsList[i] = append(detectionsList[i], translate.Detection{Language: tag, Confidence: float64(l.GetConfidence())})
+		}
+	}
+	return detectionsList, nil
+}
+
+func (c *advancedClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	resp, err := c.client.GetSupportedLanguages(ctx, &translatepb.GetSupportedLanguagesRequest{
+		Parent:              c.parent,
+		DisplayLanguageCode: target.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var langs []translate.Language
+	for _, l := range resp.GetLanguages() {
+		tag, err := language.Parse(l.GetLanguageCode())
+		if err != nil {
+			continue
+		}
+		langs = append(langs, translate.Language{Name: l.GetDisplayName(), Tag: tag})
+	}
+	return langs, nil
+}
+
+func (c *advancedClient) Close() error {
+	return c.client.Close()
+}
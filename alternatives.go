@@ -0,0 +1,64 @@
+package gtrans
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// ErrAlternativesUnsupported is returned by TranslateAlternatives if the
+// backend doesn't provide alternative translations.
+var ErrAlternativesUnsupported = errors.New("alternative translations are not supported by the backend")
+
+// alternativesTranslator is implemented by Translators which provide
+// alternative translations.
+type alternativesTranslator interface {
+	TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error)
+}
+
+// unwrapper is implemented by Translators wrapping another Translator.
+type unwrapper interface {
+	Unwrap() Translator
+}
+
+// TranslateAlternatives returns up to n alternative translations of input
+// other than the translation of Translate. Wrapper Translators such as
+// NewRetryClient are looked through. It returns ErrAlternativesUnsupported
+// if the backend doesn't provide them. Only the LibreTranslate backend
+// provides alternatives for now.
+func TranslateAlternatives(ctx context.Context, client Translator, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	for {
+		if a, ok := client.(alternativesTranslator); ok {
+			return a.TranslateAlternatives(ctx, input, target, opts, n)
+		}
+		u, ok := client.(unwrapper)
+		if !ok {
+			return nil, ErrAlternativesUnsupported
+		}
+		client = u.Unwrap()
+	}
+}
+
+// SupportsAlternatives reports whether the backend of client provides
+// alternative translations.
+func SupportsAlternatives(client Translator) bool {
+	for {
+		u, ok := client.(unwrapper)
+		if !ok {
+			break
+		}
+		client = u.Unwrap()
+	}
+	_, ok := client.(alternativesTranslator)
+	return ok
+}
+
+// WithAlternatives makes TranslateAll return up to n alternative
+// translations of each input in Translation.Alternatives if the backend
+// provides them. They are requested for each input in addition to the
+// translation.
+func WithAlternatives(n int) Option {
+	return func(c *config) { c.alternatives = n }
+}
@@ -0,0 +1,192 @@
+package gtrans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// DefaultAzureEndpoint is the global endpoint of Azure AI Translator
+// (Microsoft Translator Text API v3).
+const DefaultAzureEndpoint = "https://api.cognitive.microsofttranslator.com"
+
+// azureClient is a Translator using Azure AI Translator.
+//
+// https://learn.microsoft.com/azure/ai-services/translator/reference/v3-0-reference
+type azureClient struct {
+	apiKey     string
+	region     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewAzureClient returns a Translator using Azure AI Translator
+// authenticated with apiKey. region is the region of the Translator
+// resource (e.g. eastus) and can be empty for global resources. endpoint is
+// DefaultAzureEndpoint if it's empty.
+func NewAzureClient(apiKey, region, endpoint string) (Translator, error) {
+	if apiKey == "" {
+		return nil, errors.New("AZURE_TRANSLATOR_KEY is not set")
+	}
+	if endpoint == "" {
+		endpoint = DefaultAzureEndpoint
+	}
+	return &azureClient{
+		apiKey:     apiKey,
+		region:     region,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+type azureText struct {
+	Text string `json:"Text"`
+}
+
+func azureTexts(inputs []string) []azureText {
+	texts := make([]azureText, len(inputs))
+	for i, input := range inputs {
+		texts[i] = azureText{Text: input}
+	}
+	return texts
+}
+
+type azureDetection struct {
+	Language string `json:"language"`
+	// Score is in the range of 0 to 1.
+	Score float64 `json:"score"`
+}
+
+func (c *azureClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	q := url.Values{"api-version": {"3.0"}, "to": {azureLang(target)}}
+	if opts != nil {
+		if opts.Source != language.Und {
+			q.Set("from", azureLang(opts.Source))
+		}
+		if opts.Format == translate.HTML {
+			q.Set("textType", "html")
+		}
+	}
+	var resp []struct {
+		DetectedLanguage *azureDetection `json:"detectedLanguage"`
+		Translations     []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/translate?"+q.Encode(), nil, azureTexts(inputs), &resp); err != nil {
+		return nil, err
+	}
+	translations := make([]translate.Translation, len(resp))
+	for i, r := range resp {
+		if len(r.Translations) > 0 {
+			translations[i].Text = r.Translations[0].Text
+		}
+		if r.DetectedLanguage != nil {
+			translations[i].Source = parseAzureLang(r.DetectedLanguage.Language)
+		} else if opts != nil {
+			translations[i].Source = opts.Source
+		}
+	}
+	return translations, nil
+}
+
+func (c *azureClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	var resp []struct {
+		azureDetection
+		Alternatives []azureDetection `json:"alternatives"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/detect?api-version=3.0", nil, azureTexts(inputs), &resp); err != nil {
+		return nil, err
+	}
+	detectionsList := make([][]translate.Detection, len(resp))
+	for i, r := range resp {
+		for _, d := range append([]azureDetection{r.azureDetection}, r.Alternatives...) {
+			detectionsList[i] = append(detectionsList[i], translate.Detection{Language: parseAzureLang(d.Language), Confidence: d.Score})
+		}
+	}
+	return detectionsList, nil
+}
+
+func (c *azureClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	// Names are localized by Accept-Language.
+	header := http.Header{"Accept-Language": {target.String()}}
+	var resp struct {
+		Translation map[string]struct {
+			Name string `json:"name"`
+		} `json:"translation"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/languages?api-version=3.0&scope=translation", header, nil, &resp); err != nil {
+		return nil, err
+	}
+	langs := make([]translate.Language, 0, len(resp.Translation))
+	for code, l := range resp.Translation {
+		langs = append(langs, translate.Language{Name: l.Name, Tag: parseAzureLang(code)})
+	}
+	// Map iteration order is random.
+	sort.Slice(langs, func(i, j int) bool { return langs[i].Tag.String() < langs[j].Tag.String() })
+	return langs, nil
+}
+
+func (c *azureClient) Close() error {
+	return nil
+}
+
+// do sends a request with the subscription key. header is added to the
+// request and can be nil.
+func (c *azureClient) do(ctx context.Context, method, path string, header http.Header, body, out interface{}) error {
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Ocp-Apim-Subscription-Key", c.apiKey)
+	if c.region != "" {
+		header.Set("Ocp-Apim-Subscription-Region", c.region)
+	}
+	if err := doJSON(ctx, c.httpClient, method, c.endpoint+path, header, body, out); err != nil {
+		return fmt.Errorf("azure: %w", err)
+	}
+	return nil
+}
+
+// azureLang returns Azure language code of tag. Azure uses zh-Hans and
+// zh-Hant for Chinese and has regional variants only for some languages
+// such as pt-pt and fr-ca.
+func azureLang(tag language.Tag) string {
+	base, _ := tag.Base()
+	switch base.String() {
+	case "zh":
+		if script, _ := tag.Script(); script.String() == "Hant" {
+			return "zh-Hant"
+		}
+		return "zh-Hans"
+	case "pt":
+		if region, conf := tag.Region(); conf == language.Exact && region.String() == "PT" {
+			return "pt-pt"
+		}
+	case "fr":
+		if region, conf := tag.Region(); conf == language.Exact && region.String() == "CA" {
+			return "fr-ca"
+		}
+	case "sr", "mn":
+		if script, conf := tag.Script(); conf == language.Exact {
+			return base.String() + "-" + script.String()
+		}
+	}
+	return base.String()
+}
+
+// parseAzureLang parses Azure language code. It returns language.Und if it's
+// invalid.
+func parseAzureLang(code string) language.Tag {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
@@ -0,0 +1,145 @@
+package gtrans
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// cacheClient is a Translator which caches translations on disk.
+type cacheClient struct {
+	Translator
+	dir       string
+	namespace string
+}
+
+// NewCacheClient returns a Translator which caches translations of client in
+// dir. Translations are keyed by input, source and target languages, model,
+// format and namespace, which distinguishes translations of different
+// backends. Only inputs which are not cached are sent to client. Errors of
+// the cache are ignored so that they don't break translation.
+func NewCacheClient(client Translator, dir, namespace string) Translator {
+	return &cacheClient{Translator: client, dir: dir, namespace: namespace}
+}
+
+type cacheEntry struct {
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"`
+	Model  string `json:"model,omitempty"`
+}
+
+func (c *cacheClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	translations := make([]translate.Translation, len(inputs))
+	keys := make([]string, len(inputs))
+	var misses []string
+	var missIndices []int
+	for i, input := range inputs {
+		keys[i] = c.key(input, target, opts)
+		t, ok := c.load(keys[i])
+		if !ok {
+			misses = append(misses, input)
+			missIndices = append(missIndices, i)
+			continue
+		}
+		translations[i] = t
+	}
+	debugf("cache: %d hits, %d misses", len(inputs)-len(misses), len(misses))
+	if len(misses) == 0 {
+		return translations, nil
+	}
+	ts, err := c.Translator.Translate(ctx, misses, target, opts)
+	if err != nil {
+		return nil, err
+	}
+	for j, t := range ts {
+		i := missIndices[j]
+		translations[i] = t
+		c.store(keys[i], t)
+	}
+	return translations, nil
+}
+
+// Unwrap returns the cached client. Alternative translations are not cached.
+func (c *cacheClient) Unwrap() Translator {
+	return c.Translator
+}
+
+func (c *cacheClient) key(input string, target language.Tag, opts *translate.Options) string {
+	k := struct {
+		Namespace string
+		Input     string
+		Target    string
+		Source    string
+		Format    string
+		Model     string
+	}{Namespace: c.namespace, Input: input, Target: target.String()}
+	if opts != nil {
+		if opts.Source != language.Und {
+			k.Source = opts.Source.String()
+		}
+		k.Format = string(opts.Format)
+		k.Model = opts.Model
+	}
+	b, _ := json.Marshal(k)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *cacheClient) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+func (c *cacheClient) load(key string) (translate.Translation, bool) {
+	b, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return translate.Translation{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return translate.Translation{}, false
+	}
+	t := translate.Translation{Text: e.Text, Model: e.Model}
+	if e.Source != "" {
+		t.Source, _ = language.Parse(e.Source)
+	}
+	return t, true
+}
+
+func (c *cacheClient) store(key string, t translate.Translation) {
+	e := cacheEntry{Text: t.Text, Model: t.Model}
+	if t.Source != language.Und {
+		e.Source = t.Source.String()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	// Write to a temporary file and rename it so that concurrent processes
+	// don't read a partially written entry.
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+	}
+}
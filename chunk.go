@@ -0,0 +1,133 @@
+package gtrans
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// DefaultChunkSize is the recommended maximum number of characters per
+// request of Cloud Translation API.
+//
+// https://cloud.google.com/translate/quotas
+const DefaultChunkSize = 5000
+
+// MaxRequestChars is the maximum number of characters per request of Cloud
+// Translation API.
+//
+// https://cloud.google.com/translate/quotas
+const MaxRequestChars = 30000
+
+// sentenceEnds is a list of separators which end a sentence.
+var sentenceEnds = []string{". ", "! ", "? ", "。", "！", "？"}
+
+// SplitChunks splits text into chunks of at most size characters (runes).
+// It splits text at newline or sentence boundary if possible so that each
+// chunk can be translated independently. Concatenating the chunks returns
+// the original text.
+func SplitChunks(text string, size int) []string {
+	var chunks []string
+	for utf8.RuneCountInString(text) > size {
+		end := runeOffset(text, size)
+		i := lastBoundary(text[:end])
+		if i <= 0 {
+			i = end
+		}
+		chunks = append(chunks, text[:i])
+		text = text[i:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// runeOffset returns the byte offset of n-th rune in s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
+
+// lastBoundary returns the byte offset just after the last newline, sentence
+// end or space in s, in order of preference. It returns -1 if not found.
+func lastBoundary(s string) int {
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return i + 1
+	}
+	end := -1
+	for _, sep := range sentenceEnds {
+		if i := strings.LastIndex(s, sep); i >= 0 && i+len(sep) > end {
+			end = i + len(sep)
+		}
+	}
+	if end >= 0 {
+		return end
+	}
+	if i := strings.LastIndexFunc(s, unicode.IsSpace); i >= 0 {
+		_, n := utf8.DecodeRuneInString(s[i:])
+		return i + n
+	}
+	return -1
+}
+
+// chunk is a part of an input.
+type chunk struct {
+	input int    // index of the input
+	text  string // text to translate without trailing spaces
+	space string // trailing spaces of the chunk
+}
+
+// translateChunked translates inputs by splitting them into chunks of at
+// most size characters. Chunks are sent in as few requests as possible and
+// translated chunks are concatenated in order.
+func translateChunked(ctx context.Context, client Translator, inputs []string, target language.Tag, opts *translate.Options, size int) ([]translate.Translation, error) {
+	if size <= 0 {
+		return client.Translate(ctx, inputs, target, opts)
+	}
+
+	var chunks []chunk
+	for i, input := range inputs {
+		for _, c := range SplitChunks(input, size) {
+			// Translation API may drop trailing spaces, so keep them aside.
+			text := strings.TrimRightFunc(c, unicode.IsSpace)
+			chunks = append(chunks, chunk{input: i, text: text, space: c[len(text):]})
+		}
+	}
+
+	results := make([]translate.Translation, len(inputs))
+	for start := 0; start < len(chunks); {
+		end, n := start, 0
+		for end < len(chunks) && (end == start || n+utf8.RuneCountInString(chunks[end].text) <= size) {
+			n += utf8.RuneCountInString(chunks[end].text)
+			end++
+		}
+		texts := make([]string, end-start)
+		for i, c := range chunks[start:end] {
+			texts[i] = c.text
+		}
+		translations, err := client.Translate(ctx, texts, target, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i, t := range translations {
+			c := chunks[start+i]
+			r := &results[c.input]
+			r.Text += t.Text + c.space
+			if r.Source == language.Und {
+				r.Source = t.Source
+			}
+			r.Model = t.Model
+		}
+		start = end
+	}
+	return results, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// batchJob is a file to translate of -batch-file.
+type batchJob struct {
+	input  string
+	langs  []string
+	output string
+}
+
+// loadBatchFile loads a -batch-file. Each line of the file is an input file
+// optionally followed by a target language and an output file separated by
+// whitespaces. Empty lines and lines starting with # are ignored. e.g.
+//
+//	# input    lang  output
+//	README.md
+//	intro.md   fr
+//	guide.md   ja    docs/ja/guide.md
+//
+// The target languages are targetLangs or the ones of langMap matching the
+// input file if the language is omitted, and the output file is the input
+// file with the language before its extension (e.g. README.ja.md) if it's
+// omitted. Paths are relative to the current directory.
+func loadBatchFile(path string, targetLangs []string, langMap []langMapEntry) ([]batchJob, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load -batch-file: %v", err)
+	}
+	defer f.Close()
+	var jobs []batchJob
+	s := bufio.NewScanner(f)
+	for lnum := 1; s.Scan(); lnum++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("%s:%d: must be <input> [<lang> [<output>]]", path, lnum)
+		}
+		job := batchJob{input: fields[0], langs: targetLangs}
+		if lang, ok := matchLangMap(langMap, job.input); ok {
+			job.langs = []string{lang}
+		}
+		if len(fields) > 1 {
+			lang, err := resolveLang(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid target language: %v", path, lnum, err)
+			}
+			job.langs = []string{lang}
+		}
+		if len(fields) > 2 {
+			job.output = fields[2]
+			if filepath.Clean(job.output) == filepath.Clean(job.input) {
+				return nil, fmt.Errorf("%s:%d: output file must be different from the input file", path, lnum)
+			}
+			jobs = append(jobs, job)
+			continue
+		}
+		// Translations into each language are written to their own files.
+		for _, lang := range job.langs {
+			ext := filepath.Ext(job.input)
+			jobs = append(jobs, batchJob{
+				input:  job.input,
+				langs:  []string{lang},
+				output: strings.TrimSuffix(job.input, ext) + "." + lang + ext,
+			})
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("-batch-file %s has no input files", path)
+	}
+	return jobs, nil
+}
+
+// runBatch translates the files of jobs with -concurrency workers sharing
+// client. Each file is translated serially so that there are at most
+// -concurrency requests at a time. A failed file doesn't stop the rest. It
+// logs the result of each file after all of them finish and returns an error
+// if some of them fail. -timeout applies to each file.
+func runBatch(ctx context.Context, client gtrans.Translator, opts options, jobs []batchJob) error {
+	opts.copy = false
+	opts.notify = false
+	workers := opts.concurrency
+	opts.concurrency = 1
+	inEnc, _ := lookupEncoding(opts.inEncoding)
+	outEnc, _ := lookupEncoding(opts.outEncoding)
+	errs := make([]error, len(jobs))
+	jobc := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobc {
+				errs[i] = translateFile(ctx, client, opts, jobs[i], inEnc, outEnc)
+			}
+		}()
+	}
+loop:
+	for i := range jobs {
+		select {
+		case jobc <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobc)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return contextError(ctx, err, opts.timeout)
+	}
+
+	failed := 0
+	for i, job := range jobs {
+		if errs[i] != nil {
+			failed++
+			opts.log.Errorf("failed: %s -> %s: %v", job.input, job.output, errs[i])
+			continue
+		}
+		opts.log.Infof("ok: %s -> %s", job.input, job.output)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to translate %d of %d files", failed, len(jobs))
+	}
+	opts.log.Infof("translated %d files", len(jobs))
+	return nil
+}
+
+// translateFile translates the input file of job and writes the result to
+// its output file. The directory of the output file is created if it doesn't
+// exist. Input and output are converted with inEnc and outEnc if
+// they are not nil.
+func translateFile(ctx context.Context, client gtrans.Translator, opts options, job batchJob, inEnc, outEnc encoding.Encoding) (err error) {
+	f, err := os.Open(job.input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if inEnc != nil {
+		r = transform.NewReader(r, inEnc.NewDecoder())
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	inputs := []string{strings.TrimPrefix(string(b), utf8BOM)}
+	if opts.nfc {
+		inputs[0] = norm.NFC.String(inputs[0])
+	}
+	if err := checkMaxChars(opts, inputs); err != nil {
+		return err
+	}
+
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	if err := os.MkdirAll(filepath.Dir(job.output), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(job.output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	var w io.Writer = out
+	if outEnc != nil {
+		tw := transform.NewWriter(w, encoding.ReplaceUnsupported(outEnc.NewEncoder()))
+		defer func() {
+			if cerr := tw.Close(); err == nil && cerr != nil {
+				err = cerr
+			}
+		}()
+		w = tw
+	}
+	if isBlank(inputs) {
+		// Nothing to translate. Don't waste API quota.
+		return nil
+	}
+	err = runTranslation(ctx, w, client, opts, job.langs, inputs)
+	return authError(contextError(ctx, err, opts.timeout), opts)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ANSI escape sequences of -color output.
+const (
+	colorOriginal    = "\x1b[36m" // cyan
+	colorTranslation = "\x1b[32m" // green
+	colorReset       = "\x1b[0m"
+)
+
+// useColor reports whether output to w is colored for -color mode. In auto
+// mode, output is colored only if w is a terminal, NO_COLOR is not set and
+// TERM is not dumb. https://no-color.org/
+func useColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+	default:
+		return false, fmt.Errorf("invalid -color value %q: must be auto, always or never", mode)
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false, nil
+	}
+	return isTerminal(w), nil
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize returns s in color if opts enables colored output.
+func colorize(opts options, color, s string) string {
+	if !opts.colored {
+		return s
+	}
+	return color + s + colorReset
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// completionLangs returns language codes of gtrans.KnownLanguages for
+// completion of -to and -from. They are bundled to complete them without API
+// requests.
+func completionLangs() []string {
+	codes := make([]string, len(gtrans.KnownLanguages))
+	for i, l := range gtrans.KnownLanguages {
+		codes[i] = l.Code
+	}
+	return codes
+}
+
+// langFlags is a set of flags defined by langFlagVar.
+var langFlags = make(map[string]bool)
+
+// writeCompletion writes a completion script for shell.
+func writeCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		writeBashCompletion(w)
+	case "zsh":
+		writeZshCompletion(w)
+	case "fish":
+		writeFishCompletion(w)
+	default:
+		return checkShell(shell)
+	}
+	return nil
+}
+
+// checkShell returns an error if writeCompletion doesn't support shell.
+func checkShell(shell string) error {
+	switch shell {
+	case "bash", "zsh", "fish":
+		return nil
+	}
+	return fmt.Errorf("unsupported shell %q for -completion: must be bash, zsh or fish", shell)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// writeBashCompletion writes a bash completion script to be used as
+// `eval "$(gtrans -completion bash)"`.
+func writeBashCompletion(w io.Writer) {
+	var flags, langOpts []string
+	flag.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, "-"+f.Name)
+		if langFlags[f.Name] {
+			langOpts = append(langOpts, "-"+f.Name, "--"+f.Name)
+		}
+	})
+	fmt.Fprintf(w, `_gtrans() {
+	local cur prev
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	case "$prev" in
+	%s)
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+		;;
+	esac
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+	fi
+}
+complete -o default -F _gtrans gtrans
+`, strings.Join(langOpts, "|"), strings.Join(completionLangs(), " "), strings.Join(flags, " "))
+}
+
+// writeZshCompletion writes a zsh completion script to be saved as _gtrans in
+// $fpath.
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintln(w, "#compdef gtrans")
+	fmt.Fprintln(w, "_arguments \\")
+	// Characters which have special meaning in _arguments descriptions.
+	r := strings.NewReplacer("'", "", "[", "(", "]", ")", ":", "", "\n", " ")
+	flag.VisitAll(func(f *flag.Flag) {
+		spec := fmt.Sprintf("-%s[%s]", f.Name, r.Replace(f.Usage))
+		switch {
+		case langFlags[f.Name]:
+			spec += fmt.Sprintf(":language:(%s)", strings.Join(completionLangs(), " "))
+		case !isBoolFlag(f):
+			spec += ":value:_files"
+		}
+		fmt.Fprintf(w, "\t'%s' \\\n", spec)
+	})
+	fmt.Fprintln(w, "\t'*:input text:'")
+}
+
+// writeFishCompletion writes a fish completion script to be used as
+// `gtrans -completion fish | source`.
+func writeFishCompletion(w io.Writer) {
+	r := strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", " ")
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, "complete -c gtrans -o %s -d '%s'", f.Name, r.Replace(f.Usage))
+		switch {
+		case langFlags[f.Name]:
+			fmt.Fprintf(w, " -x -a '%s'", strings.Join(completionLangs(), " "))
+		case !isBoolFlag(f):
+			fmt.Fprint(w, " -r")
+		}
+		fmt.Fprintln(w)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// fileConfig represents the config file. e.g.
+//
+//	lang = "ja"
+//	second_lang = "en"
+//	api_key = "<Your Google Translate API Key>"
+//	timeout = "10s"
+//	output = "json"
+type fileConfig struct {
+	// Lang is the default target language.
+	Lang string `toml:"lang"`
+	// SecondLang is the second language.
+	SecondLang string `toml:"second_lang"`
+	// APIKey is Google Translate API key.
+	APIKey string `toml:"api_key"`
+	// Timeout is the timeout of API requests.
+	Timeout duration `toml:"timeout"`
+	// Output is the output format. "text" or "json".
+	Output string `toml:"output"`
+}
+
+// duration is time.Duration which can be decoded from a string like "10s".
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	var err error
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
+}
+
+// configDir returns the config directory of gtrans.
+// $XDG_CONFIG_HOME/gtrans or ~/.config/gtrans.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gtrans"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gtrans"), nil
+}
+
+// cacheDir returns the directory of translation cache under the config
+// directory.
+func cacheDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
+
+// loadConfig loads the config file and returns it with its path. It returns
+// empty config if the config file doesn't exist.
+func loadConfig() (*fileConfig, string, error) {
+	cfg := &fileConfig{}
+	dir, err := configDir()
+	if err != nil {
+		// Config file is optional.
+		return cfg, "", nil
+	}
+	path := filepath.Join(dir, "config.toml")
+	if _, err := toml.DecodeFile(path, cfg); err != nil && !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("failed to load config file %s: %v", path, err)
+	}
+	switch cfg.Output {
+	case "", "text", "json":
+	default:
+		return nil, "", fmt.Errorf("invalid output %q in config file %s: must be text or json", cfg.Output, path)
+	}
+	return cfg, path, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync/atomic"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// countClient is a Translator which counts texts of translation requests
+// for -count-only. Texts are counted after splitting, chunking and
+// deduplication, which is the number of texts sent to the API unless they
+// are cached.
+type countClient struct {
+	gtrans.Translator
+	n int64
+}
+
+func (c *countClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	atomic.AddInt64(&c.n, int64(len(inputs)))
+	return c.Translator.Translate(ctx, inputs, target, opts)
+}
+
+func (c *countClient) Unwrap() gtrans.Translator {
+	return c.Translator
+}
+
+// runCount translates inputs with client and writes only the number of
+// translated texts. Translations into each target language are counted.
+func runCount(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, targetLangs []string, inputs []string) error {
+	c := &countClient{Translator: client}
+	opts.copy = false
+	opts.notify = false
+	opts.roundTrip = false
+	opts.alternatives = 0
+	if err := runTranslation(ctx, ioutil.Discard, c, opts, targetLangs, inputs); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, atomic.LoadInt64(&c.n))
+	return err
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// csvDelimiter returns the field delimiter of -csv. It's tab for *.tsv input
+// files and comma otherwise unless -csv-delimiter is given.
+func csvDelimiter(opts options) (rune, error) {
+	switch opts.csvDelimiter {
+	case "":
+		if strings.EqualFold(filepath.Ext(opts.inputFile), ".tsv") {
+			return '\t', nil
+		}
+		return ',', nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(opts.csvDelimiter)
+	if size != len(opts.csvDelimiter) || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("invalid -csv-delimiter %q: must be a character or tab", opts.csvDelimiter)
+	}
+	return r, nil
+}
+
+// runCSV translates cells of -column in CSV (or TSV) input and writes the
+// CSV with the other columns intact. The header row is not translated unless
+// -csv-header is given. Cells are translated in one request. Cells which fail
+// to translate are kept as they are.
+func runCSV(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, targetLang string, input string) error {
+	comma, err := csvDelimiter(opts)
+	if err != nil {
+		return &configError{err: err}
+	}
+	r := csv.NewReader(strings.NewReader(input))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	// TSV often has bare quotes in fields.
+	r.LazyQuotes = comma == '\t'
+	records, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV: %v", err)
+	}
+
+	col := opts.column - 1
+	var cells []string
+	var rows []int
+	for i, record := range records {
+		if (i == 0 && !opts.csvHeader) || col >= len(record) || strings.TrimSpace(record[col]) == "" {
+			continue
+		}
+		cells = append(cells, record[col])
+		rows = append(rows, i)
+	}
+	opts.stats.addInputs(len(cells))
+	if len(cells) > 0 {
+		var ts []gtrans.Translation
+		ts, err = translateAll(ctx, cells, targetLang, opts, translateOptions(client, opts, 1))
+		if err != nil && !errors.As(err, new(*partialError)) {
+			return err
+		}
+		if opts.htmlToText {
+			htmlToText(ts, nil)
+		}
+		for i, t := range ts {
+			if t.Text == "" {
+				// Keep the original cell if it failed to translate.
+				continue
+			}
+			records[rows[i]][col] = t.Text
+		}
+	}
+
+	opts.progress.finish()
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.UseCRLF = strings.Contains(input, "\r\n")
+	if werr := cw.WriteAll(records); werr != nil {
+		return werr
+	}
+	return err
+}
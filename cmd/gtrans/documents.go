@@ -0,0 +1,29 @@
+package main
+
+import "strings"
+
+// splitDocuments splits text into documents separated by lines of delim for
+// -doc-delimiter. Line endings before delimiter lines are not part of the
+// documents. An empty document after the last delimiter is dropped.
+func splitDocuments(text, delim string) []string {
+	var docs []string
+	var doc strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.TrimRight(line, "\r\n") == delim {
+			docs = append(docs, trimEOL(doc.String()))
+			doc.Reset()
+			continue
+		}
+		doc.WriteString(line)
+	}
+	if last := trimEOL(doc.String()); last != "" || len(docs) == 0 {
+		docs = append(docs, last)
+	}
+	return docs
+}
+
+// trimEOL removes a trailing line ending of s.
+func trimEOL(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
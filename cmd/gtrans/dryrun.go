@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// pricePerMillionChars is the price of Cloud Translation (Basic and Advanced)
+// in USD per million characters.
+// https://cloud.google.com/translate/pricing
+const pricePerMillionChars = 20
+
+// dryRunClient is a Translator which counts billable characters of requests
+// instead of calling the API. Translations are the same as inputs.
+type dryRunClient struct {
+	mu    sync.Mutex
+	chars int
+}
+
+func (c *dryRunClient) count(inputs []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Characters are billed by code points, not bytes.
+	c.chars += countChars(inputs)
+}
+
+func (c *dryRunClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	c.count(inputs)
+	translations := make([]translate.Translation, len(inputs))
+	for i, input := range inputs {
+		translations[i] = translate.Translation{Text: input}
+	}
+	return translations, nil
+}
+
+func (c *dryRunClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	c.count(inputs)
+	return make([][]translate.Detection, len(inputs)), nil
+}
+
+func (c *dryRunClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	return nil, nil
+}
+
+func (c *dryRunClient) Close() error {
+	return nil
+}
+
+// runDryRun writes the number of billable characters and estimated cost of
+// the requests without calling the API. Requests are made in the same way as
+// actual run including splitting, chunking and multiple target languages.
+// Requests of -roundtrip, -verbose and -alternatives are not counted. With
+// -count-only, it writes the number of texts instead.
+func runDryRun(ctx context.Context, w io.Writer, opts options, targetLangs []string, inputs []string) error {
+	client := &dryRunClient{}
+	opts.copy = false
+	opts.verbose = false
+	opts.roundTrip = false
+	opts.notify = false
+	opts.alternatives = 0
+	if opts.countOnly {
+		return runCount(ctx, w, client, opts, targetLangs, inputs)
+	}
+	var err error
+	if opts.detect {
+		err = runDetection(ctx, ioutil.Discard, client, opts, inputs)
+	} else {
+		err = runTranslation(ctx, ioutil.Discard, client, opts, targetLangs, inputs)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "characters: %d\n", client.chars)
+	fmt.Fprintf(w, "estimated cost: $%.4f (at $%d per million characters)\n", float64(client.chars)*pricePerMillionChars/1e6, pricePerMillionChars)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// lookupEncoding returns the encoding of name such as shift_jis, euc-jp and
+// gbk for -input-encoding and -output-encoding. Names are the ones of the
+// WHATWG Encoding Standard. It returns nil for UTF-8, which needs no
+// conversion.
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	if name == "" || strings.EqualFold(name, "utf-8") || strings.EqualFold(name, "utf8") {
+		return nil, nil
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding %q (e.g. shift_jis, euc-jp, gbk, big5, euc-kr, windows-1252)", name)
+	}
+	if n, _ := htmlindex.Name(enc); n == "utf-8" {
+		return nil, nil
+	}
+	return enc, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// explainClient is a Translator which writes parameters and metadata of
+// each request and response for -explain. Like logClient, it wraps the
+// backend client so that each retry is explained.
+type explainClient struct {
+	gtrans.Translator
+	mu      sync.Mutex
+	w       io.Writer
+	backend string
+	// secrets are API keys masked in errors.
+	secrets []string
+}
+
+func (c *explainClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	start := time.Now()
+	translations, err := c.Translator.Translate(ctx, inputs, target, opts)
+	var b strings.Builder
+	source, format, model := "auto", "text", "default"
+	if opts != nil {
+		if opts.Source != language.Und {
+			source = opts.Source.String()
+		}
+		if opts.Format != "" {
+			format = string(opts.Format)
+		}
+		if opts.Model != "" {
+			model = opts.Model
+		}
+	}
+	fmt.Fprintf(&b, "explain: translate request: backend=%s target=%s source=%s format=%s model=%s inputs=%d chars=%d\n", c.backend, target, source, format, model, len(inputs), countChars(inputs))
+	if c.response(&b, start, err) {
+		for i, t := range translations {
+			fmt.Fprintf(&b, "explain:   [%d] input chars=%d output chars=%d source=%s model=%s\n", i+1, utf8.RuneCountInString(inputs[i]), utf8.RuneCountInString(t.Text), tagOrNone(t.Source), valueOrNone(t.Model))
+		}
+	}
+	c.write(b.String())
+	return translations, err
+}
+
+func (c *explainClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	start := time.Now()
+	detections, err := c.Translator.DetectLanguage(ctx, inputs)
+	var b strings.Builder
+	fmt.Fprintf(&b, "explain: detect request: backend=%s inputs=%d chars=%d\n", c.backend, len(inputs), countChars(inputs))
+	if c.response(&b, start, err) {
+		for i, ds := range detections {
+			for _, d := range ds {
+				fmt.Fprintf(&b, "explain:   [%d] language=%s confidence=%.2f reliable=%v\n", i+1, tagOrNone(d.Language), d.Confidence, d.IsReliable)
+			}
+		}
+	}
+	c.write(b.String())
+	return detections, err
+}
+
+func (c *explainClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	start := time.Now()
+	langs, err := c.Translator.SupportedLanguages(ctx, target)
+	var b strings.Builder
+	fmt.Fprintf(&b, "explain: languages request: backend=%s target=%s\n", c.backend, target)
+	if c.response(&b, start, err) {
+		fmt.Fprintf(&b, "explain:   languages=%d\n", len(langs))
+	}
+	c.write(b.String())
+	return langs, err
+}
+
+func (c *explainClient) TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	start := time.Now()
+	alts, err := gtrans.TranslateAlternatives(ctx, c.Translator, input, target, opts, n)
+	var b strings.Builder
+	fmt.Fprintf(&b, "explain: alternatives request: backend=%s target=%s chars=%d n=%d\n", c.backend, target, utf8.RuneCountInString(input), n)
+	if c.response(&b, start, err) {
+		fmt.Fprintf(&b, "explain:   alternatives=%d\n", len(alts))
+	}
+	c.write(b.String())
+	return alts, err
+}
+
+func (c *explainClient) Unwrap() gtrans.Translator {
+	return c.Translator
+}
+
+// response writes the status line of a response and reports whether the
+// request succeeded.
+func (c *explainClient) response(b *strings.Builder, start time.Time, err error) bool {
+	d := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(b, "explain: response after %v: error: %s\n", d, maskSecrets(err.Error(), c.secrets))
+		return false
+	}
+	fmt.Fprintf(b, "explain: response after %v\n", d)
+	return true
+}
+
+// write writes s at once not to mix explanations of concurrent requests.
+func (c *explainClient) write(s string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	io.WriteString(c.w, s)
+}
+
+func tagOrNone(tag language.Tag) string {
+	if tag == language.Und {
+		return "-"
+	}
+	return tag.String()
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
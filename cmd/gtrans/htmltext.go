@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// htmlToText strips tags of translations and round trip translations of
+// HTML for -html-to-text. Tags guide the API through sentence boundaries but
+// only readable text is written.
+func htmlToText(translations []gtrans.Translation, backs []string) {
+	for i := range translations {
+		translations[i].Text = stripHTML(translations[i].Text)
+	}
+	for i := range backs {
+		backs[i] = stripHTML(backs[i])
+	}
+}
+
+// stripHTML returns visible text of HTML s. It returns s as it is if it
+// fails to parse s.
+func stripHTML(s string) string {
+	text, err := gtrans.HTMLText(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	return text
+}
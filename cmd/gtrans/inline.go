@@ -0,0 +1,28 @@
+package main
+
+import "regexp"
+
+// inlineTargetRe matches a target language directive of -inline-target such
+// as " ::ja" at the end of input.
+var inlineTargetRe = regexp.MustCompile(`(?:^|\s+)::([A-Za-z0-9-]+(?:,[A-Za-z0-9-]+)*)[ \t]*(\r?\n)?$`)
+
+// stripInlineTarget removes a trailing target language directive from the
+// last input and returns the target languages of the directive. It returns
+// "" if there is no directive.
+func stripInlineTarget(inputs []string) string {
+	if len(inputs) == 0 {
+		return ""
+	}
+	last := inputs[len(inputs)-1]
+	m := inlineTargetRe.FindStringSubmatchIndex(last)
+	if m == nil {
+		return ""
+	}
+	// Keep the line ending.
+	eol := ""
+	if m[4] >= 0 {
+		eol = last[m[4]:m[5]]
+	}
+	inputs[len(inputs)-1] = last[:m[0]] + eol
+	return last[m[2]:m[3]]
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/haya14busa/gtrans"
+)
+
+const interactiveHelp = `Type text to translate it. Commands:
+	:to <lang>[,<lang>...]  change target languages
+	:from [<lang>]          change source language (empty means auto-detect)
+	:help                   show this help
+	:quit                   quit (or EOF)`
+
+// runInteractive reads lines from r and translates each line until EOF,
+// reusing client. Errors of each line are reported to STDERR without
+// stopping the loop. -timeout applies to each line. -no-newline is ignored.
+func runInteractive(ctx context.Context, r io.Reader, w io.Writer, client gtrans.Translator, opts options, targetLangs []string) error {
+	// Each result must end with newline to be followed by the prompt.
+	opts.noNewline = false
+	s := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !s.Scan() {
+			break
+		}
+		line := strings.TrimSpace(strings.TrimPrefix(s.Text(), utf8BOM))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			quit, err := runInteractiveCommand(line, &opts, &targetLangs)
+			if err != nil {
+				opts.log.Errorf("%v", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+		if err := translateText(ctx, w, client, opts, targetLangs, line); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			opts.log.Errorf("%v", err)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+	return s.Err()
+}
+
+// translateText translates or detects language of text and writes the
+// result. -timeout applies to it.
+func translateText(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, targetLangs []string, text string) error {
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	var err error
+	if opts.detect {
+		err = runDetection(ctx, w, client, opts, []string{text})
+	} else {
+		err = runTranslation(ctx, w, client, opts, targetLangs, []string{text})
+	}
+	return contextError(ctx, err, opts.timeout)
+}
+
+// runInteractiveCommand runs a command line such as ":to ja" of interactive
+// mode. It returns true if the command quits the loop.
+func runInteractiveCommand(line string, opts *options, targetLangs *[]string) (bool, error) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":q", ":quit":
+		return true, nil
+	case ":h", ":help":
+		fmt.Fprintln(os.Stderr, interactiveHelp)
+	case ":to":
+		if len(fields) != 2 {
+			return false, fmt.Errorf("usage: :to <lang>[,<lang>...]")
+		}
+		langs := strings.Split(fields[1], ",")
+		for i, lang := range langs {
+			code, err := resolveLang(lang)
+			if err != nil {
+				return false, fmt.Errorf("invalid target language: %v", err)
+			}
+			langs[i] = code
+		}
+		*targetLangs = langs
+	case ":from":
+		if len(fields) > 2 {
+			return false, fmt.Errorf("usage: :from [<lang>]")
+		}
+		opts.sourceLang = ""
+		if len(fields) == 2 {
+			code, err := resolveLang(fields[1])
+			if err != nil {
+				return false, fmt.Errorf("invalid source language: %v", err)
+			}
+			opts.sourceLang = code
+		}
+	default:
+		return false, fmt.Errorf("unknown command %q. Type :help to show commands", fields[0])
+	}
+	return false, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haya14busa/gtrans"
+	"golang.org/x/text/language"
+)
+
+// langMapEntry is an entry of -lang-map file.
+type langMapEntry struct {
+	pattern string
+	lang    string
+}
+
+// loadLangMap loads a -lang-map file. Each line of the file is a glob pattern
+// of file paths and a target language separated by whitespaces. Empty lines
+// and lines starting with # are ignored. e.g.
+//
+//	# pattern  lang
+//	docs/ja/*  ja
+//	*.fr.md    fr
+func loadLangMap(path string) ([]langMapEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load -lang-map file: %v", err)
+	}
+	defer f.Close()
+	var entries []langMapEntry
+	s := bufio.NewScanner(f)
+	for lnum := 1; s.Scan(); lnum++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: must be <pattern> <lang>", path, lnum)
+		}
+		if _, err := filepath.Match(fields[0], ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q: %v", path, lnum, fields[0], err)
+		}
+		lang := gtrans.NormalizeLangCode(fields[1])
+		if _, err := language.Parse(lang); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid target language %q: %v", path, lnum, fields[1], err)
+		}
+		entries = append(entries, langMapEntry{pattern: fields[0], lang: lang})
+	}
+	return entries, s.Err()
+}
+
+// matchLangMap returns the target language of the first entry matching
+// path. Patterns without a slash match the base name of path. It returns
+// false if no entries match.
+func matchLangMap(entries []langMapEntry, path string) (string, bool) {
+	path = filepath.ToSlash(filepath.Clean(path))
+	for _, e := range entries {
+		name := path
+		if !strings.Contains(e.pattern, "/") {
+			name = filepath.Base(path)
+		}
+		if ok, _ := filepath.Match(e.pattern, name); ok {
+			return e.lang, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// langNames maps language codes to human-readable names for
+// -output-lang-names.
+type langNames map[string]string
+
+// loadLangNames loads names of languages supported by client written in
+// display language.
+func loadLangNames(ctx context.Context, client gtrans.Translator, display string) (langNames, error) {
+	langs, err := gtrans.SupportedLanguages(ctx, display, gtrans.WithClient(client))
+	if err != nil {
+		return nil, err
+	}
+	names := make(langNames, len(langs))
+	for _, l := range langs {
+		names[l.Tag.String()] = l.Name
+	}
+	return names, nil
+}
+
+// name returns the name of tag. It returns the language code if the name is
+// unknown or names is nil. Regional variants such as en-US fall back to the
+// name of the base language.
+func (names langNames) name(tag language.Tag) string {
+	if name, ok := names[tag.String()]; ok {
+		return name
+	}
+	if base, conf := tag.Base(); conf != language.No {
+		if name, ok := names[base.String()]; ok {
+			return name
+		}
+	}
+	return tag.String()
+}
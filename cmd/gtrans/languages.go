@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// writeLanguages writes the bundled language codes and names of
+// gtrans.KnownLanguages for -languages, which looks up codes without API
+// requests. Use -list-languages for the languages actually supported by the
+// backend.
+func writeLanguages(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, l := range gtrans.KnownLanguages {
+		fmt.Fprintf(tw, "%s\t%s\n", l.Code, l.Name)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// runLines reads lines from r and writes the translation of each line as
+// soon as it's read until EOF so that gtrans can be used in the middle of a
+// long-running pipeline. Blank lines are written as they are to keep lines
+// aligned. -timeout applies to each line. -no-newline is ignored.
+func runLines(ctx context.Context, r io.Reader, w io.Writer, client gtrans.Translator, opts options, targetLangs []string) error {
+	opts.noNewline = false
+	s := bufio.NewScanner(r)
+	// A line can have as many characters as a request does.
+	s.Buffer(nil, utf8.UTFMax*gtrans.MaxRequestChars)
+	for first := true; s.Scan(); first = false {
+		line := strings.TrimRight(s.Text(), "\r")
+		if first {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+		if strings.TrimSpace(line) == "" {
+			fmt.Fprintln(w, line)
+			continue
+		}
+		if err := checkMaxChars(opts, []string{line}); err != nil {
+			return err
+		}
+		if err := translateText(ctx, w, client, opts, targetLangs, line); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// logClient is a Translator which logs requests and their timings for -log.
+// It wraps the backend client inside the retry client so that each retry is
+// logged as a request.
+type logClient struct {
+	gtrans.Translator
+	logger *log.Logger
+	// secrets are API keys masked in logs.
+	secrets []string
+}
+
+func (c *logClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	start := time.Now()
+	translations, err := c.Translator.Translate(ctx, inputs, target, opts)
+	c.log("translate", start, err, "target=%s inputs=%d chars=%d", target, len(inputs), countChars(inputs))
+	return translations, err
+}
+
+func (c *logClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	start := time.Now()
+	detections, err := c.Translator.DetectLanguage(ctx, inputs)
+	c.log("detect", start, err, "inputs=%d chars=%d", len(inputs), countChars(inputs))
+	return detections, err
+}
+
+func (c *logClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	start := time.Now()
+	langs, err := c.Translator.SupportedLanguages(ctx, target)
+	c.log("languages", start, err, "target=%s", target)
+	return langs, err
+}
+
+func (c *logClient) TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	start := time.Now()
+	alts, err := gtrans.TranslateAlternatives(ctx, c.Translator, input, target, opts, n)
+	c.log("alternatives", start, err, "target=%s chars=%d n=%d", target, countChars([]string{input}), n)
+	return alts, err
+}
+
+func (c *logClient) Unwrap() gtrans.Translator {
+	return c.Translator
+}
+
+func (c *logClient) log(method string, start time.Time, err error, format string, a ...interface{}) {
+	a = append([]interface{}{method}, a...)
+	a = append(a, time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		c.logger.Printf("%s: "+format+" duration=%v error: %s", append(a, maskSecrets(err.Error(), c.secrets))...)
+		return
+	}
+	c.logger.Printf("%s: "+format+" duration=%v", a...)
+}
+
+func countChars(inputs []string) int {
+	n := 0
+	for _, input := range inputs {
+		n += utf8.RuneCountInString(input)
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// logLevel is a level of diagnostic messages written to STDERR.
+type logLevel int
+
+const (
+	levelError logLevel = iota
+	levelWarn
+	levelInfo
+	levelDebug
+)
+
+// logLevelNames are names of log levels for -log-level.
+var logLevelNames = []string{"error", "warn", "info", "debug"}
+
+// logPrefixes are prefixes of messages of each log level.
+var logPrefixes = []string{"error", "warning", "info", "debug"}
+
+func parseLogLevel(s string) (logLevel, error) {
+	for i, name := range logLevelNames {
+		if s == name {
+			return logLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid -log-level value %q: must be %s", s, strings.Join(logLevelNames, ", "))
+}
+
+// leveledLogger writes diagnostic messages of at most level with their level
+// prefix (e.g. "warning: ..."). Methods of a nil leveledLogger do nothing.
+// It also receives messages of the gtrans package.
+type leveledLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level logLevel
+	// secrets are API keys masked in messages.
+	secrets []string
+}
+
+func (l *leveledLogger) logf(level logLevel, format string, a ...interface{}) {
+	if l == nil || level > l.level {
+		return
+	}
+	msg := maskSecrets(fmt.Sprintf(format, a...), l.secrets)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "%s: %s\n", logPrefixes[level], msg)
+}
+
+func (l *leveledLogger) Errorf(format string, a ...interface{}) { l.logf(levelError, format, a...) }
+func (l *leveledLogger) Warnf(format string, a ...interface{})  { l.logf(levelWarn, format, a...) }
+func (l *leveledLogger) Infof(format string, a ...interface{})  { l.logf(levelInfo, format, a...) }
+func (l *leveledLogger) Debugf(format string, a ...interface{}) { l.logf(levelDebug, format, a...) }
+
+// enabled reports whether messages of level are written.
+func (l *leveledLogger) enabled(level logLevel) bool {
+	return l != nil && level <= l.level
+}
+
+// levelWriter is an io.Writer which writes each line as a message of level
+// so that a log.Logger can write to a leveledLogger.
+type levelWriter struct {
+	l     *leveledLogger
+	level logLevel
+}
+
+func (w levelWriter) Write(b []byte) (int, error) {
+	w.l.logf(w.level, "%s", strings.TrimSuffix(string(b), "\n"))
+	return len(b), nil
+}
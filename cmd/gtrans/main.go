@@ -0,0 +1,1827 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"cloud.google.com/go/translate"
+	"github.com/atotto/clipboard"
+	openbrowser "github.com/haya14busa/go-openbrowser"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/language"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/haya14busa/gtrans"
+)
+
+const usageMessage = "" +
+	`Usage:	gtrans [flags] [input text]
+	gtrans translates input text specified by argument or STDIN using Google Translate.
+	Source language will be automatically detected unless -from is given.
+
+	export GOOGLE_TRANSLATE_API_KEY=<Your Google Translate API Key>
+
+	If GOOGLE_TRANSLATE_API_KEY is not set, gtrans uses the service account
+	credentials of GOOGLE_APPLICATION_CREDENTIALS or Application Default
+	Credentials instead.
+
+	[optional]
+	export DEEPL_API_KEY=<Your DeepL API Key. Required for -backend=deepl>
+	export LIBRETRANSLATE_URL=<Base URL of LibreTranslate (default: http://localhost:5000)>
+	export LIBRETRANSLATE_API_KEY=<LibreTranslate API Key. Required by some hosted instances>
+	export AZURE_TRANSLATOR_KEY=<Azure AI Translator key. Required for -backend=azure>
+	export AZURE_TRANSLATOR_REGION=<Region of the Azure Translator resource (e.g. eastus). Not required for global resources>
+	export AZURE_TRANSLATOR_ENDPOINT=<Azure Translator endpoint (default: https://api.cognitive.microsofttranslator.com)>
+	export GOOGLE_TRANSLATE_API_KEY_FILE=<File containing API key. Used instead of GOOGLE_TRANSLATE_API_KEY>
+	export GOOGLE_TRANSLATE_ENDPOINT=<Google Translate API endpoint. Used instead of the default endpoint>
+	export GOOGLE_CLOUD_PROJECT=<Google Cloud project. Required for -glossary>
+	export GOOGLE_TRANSLATE_LANG=<default target language (e.g. en, ja, ...)>
+	export GOOGLE_TRANSLATE_SECOND_LANG=<second language (e.g. en, ja, ...)>
+
+	Default values can also be set in $XDG_CONFIG_HOME/gtrans/config.toml
+	(~/.config/gtrans/config.toml). Flags and environment variables take
+	precedence over the config file. -config-dump shows the effective values.
+
+		lang = "ja"          # default target language
+		second_lang = "en"   # second language
+		api_key = "<Your Google Translate API Key>"
+		timeout = "10s"      # timeout of API requests
+		output = "json"      # output format (text or json)
+
+	If you set both GOOGLE_TRANSLATE_LANG and GOOGLE_TRANSLATE_SECOND_LANG,
+	gtrans automatically switches target langage.
+	GOOGLE_TRANSLATE_SECOND_LANG is ignored when multiple target languages are
+	given by -to.
+	-primary and -secondary flags do the same for a single command and take
+	precedence over the environment variables and the config file.
+
+	Example:
+		$ gtrans "Golang is awesome"
+		Golangは素晴らしいです
+		$ gtrans "Golangは素晴らしいです"
+		Golang is great
+		$ gtrans "Golangは素晴らしいです" | gtrans | gtrans | gtrans ...
+`
+
+// options holds the values of command-line flags.
+type options struct {
+	targetLang    string
+	sourceLang    string
+	primaryLang   string
+	secondaryLang string
+	inlineTarget  bool
+	doOpenBrowser bool
+	jsonOutput    bool
+	separate      bool
+	detect        bool
+	detectAll     bool
+	listLanguages bool
+	validate      bool
+	timeout       time.Duration
+	keyFile       string
+	apiKey        string
+	retries       int
+	chunk         bool
+	chunkSize     int
+	maxChars      int
+	split         string
+	showOriginal  bool
+	copy          bool
+	version       bool
+	completion    string
+	languages     bool
+	verbose       bool
+	format        string
+	model         string
+	roundTrip     bool
+	concurrency   int
+	backend       string
+	endpoint      string
+	interactive   bool
+	watch         bool
+	watchInterval time.Duration
+	notify        bool
+	markdown      bool
+	tsv           bool
+	swap          bool
+	stdinLines    bool
+	alternatives  int
+	userAgent     string
+	outLangNames  bool
+	skipSame      bool
+	rps           float64
+	maxURLLength  int
+	charsPerMin   int
+	showProgress  bool
+	showStats     bool
+	brief         bool
+	romanize      bool
+	url           bool
+	inputFile     string
+	outputFile    string
+	batchFile     string
+	appendOutput  bool
+	inEncoding    string
+	outEncoding   string
+	color         string
+	rtl           string
+	separator     string
+	docDelimiter  string
+	preserve      bool
+	preservePats  stringsFlag
+	keep          bool
+	keepDelimiter string
+	noCache       bool
+	clearCache    bool
+	noNewline     bool
+	dryRun        bool
+	countOnly     bool
+	langMap       string
+	glossary      string
+	project       string
+	location      string
+	csv           bool
+	column        int
+	csvDelimiter  string
+	csvHeader     bool
+	quiet         bool
+	nfc           bool
+	preserveSpace bool
+	minConfidence float64
+	detectSample  int
+	failConf      float64
+	logFile       string
+	logLevel      string
+	explain       bool
+	htmlToText    bool
+	configDump    bool
+
+	// Values below are not flags but resolved from environment variables
+	// and the config file.
+	secondLang string
+	// settings are the resolved configuration values and their sources.
+	settings *settings
+	// log writes diagnostic messages of -log-level to STDERR.
+	log *leveledLogger
+	// colored is true if output is colored by -color.
+	colored bool
+	// rtlMarks is true if right-to-left translations are wrapped with
+	// directional marks by -rtl.
+	rtlMarks bool
+	// langNames are language names for -output-lang-names. Language codes
+	// are written if it's nil.
+	langNames langNames
+	// stats counts usage of the API with -stats.
+	stats *stats
+	// progress reports progress of translation with -progress.
+	progress *progress
+	// keepOpen and keepClose are delimiters of -keep-delimiter.
+	keepOpen, keepClose string
+	// preserveRegexps are compiled patterns of -preserve-pattern.
+	preserveRegexps []*regexp.Regexp
+	// logger writes diagnostic logs to -log file. It's nil without -log.
+	logger *log.Logger
+	// lastSource records the detected source language for -swap. It's nil
+	// with -batch-file and -concurrency, which translate concurrently.
+	lastSource *lastSource
+}
+
+// stringsFlag is a flag which can be given multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+var opts options
+
+// langFlagVar defines a string flag which takes a language code. Shells
+// complete language codes for it.
+func langFlagVar(p *string, name, usage string) {
+	flag.StringVar(p, name, "", usage)
+	langFlags[name] = true
+}
+
+func init() {
+	langFlagVar(&opts.targetLang, "to", "target language code or name (e.g. ja or japanese). comma-separated list translates input into each language (e.g. en,ja,fr)")
+	langFlagVar(&opts.sourceLang, "from", "source language code or name (default: auto-detect)")
+	flag.BoolVar(&opts.inlineTarget, "inline-target", false, `read target language from a directive at the end of input such as "text ::ja" unless -to is given. The directive is removed from the input`)
+	langFlagVar(&opts.primaryLang, "primary", "primary language: input in other languages is translated into it. Takes precedence over GOOGLE_TRANSLATE_LANG and cannot be used with -to")
+	langFlagVar(&opts.secondaryLang, "secondary", "secondary language: input in the primary language is translated into it. Takes precedence over GOOGLE_TRANSLATE_SECOND_LANG")
+	flag.BoolVar(&opts.doOpenBrowser, "open", false, "open Google Translate in browser instead of writing translated result to STDOUT. With -copy or -notify, text is translated by the API as well for them")
+	flag.IntVar(&opts.maxURLLength, "max-url-length", 2048, "max URL length of -open. For longer text, Google Translate is opened without text and the text is copied to clipboard instead since browsers may truncate the URL. 0 means no limit")
+	flag.BoolVar(&opts.jsonOutput, "json", false, "write translated result as JSON")
+	flag.BoolVar(&opts.separate, "separate", false, "translate each argument separately")
+	flag.BoolVar(&opts.detect, "detect", false, "only print detected language and its confidence instead of translating")
+	flag.BoolVar(&opts.detectAll, "detect-all", false, "like -detect but print all candidate languages of input with their confidence in descending order")
+	flag.BoolVar(&opts.validate, "validate", false, "check target, second and source languages against supported languages of the backend before translation and suggest similar codes for unsupported ones")
+	flag.BoolVar(&opts.listLanguages, "list-languages", false, "list supported languages with their names in target language")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "timeout of API requests (0 means no timeout)")
+	flag.IntVar(&opts.retries, "retries", 3, "max number of retries on transient API errors (rate limit and server errors). Requests are not retried after -timeout")
+	flag.BoolVar(&opts.markdown, "markdown", false, "translate only prose of Markdown input keeping code blocks, inline code, link URLs and HTML untouched. Same as -split markdown")
+	flag.BoolVar(&opts.chunk, "chunk", false, "split long input into chunks at newline or sentence boundaries to respect the API limit")
+	flag.IntVar(&opts.maxChars, "max-chars", gtrans.MaxRequestChars, "max number of characters of input without -chunk. Larger input is rejected before calling the API. 0 means no limit")
+	flag.IntVar(&opts.chunkSize, "chunk-size", gtrans.DefaultChunkSize, "max number of characters per request with -chunk")
+	flag.StringVar(&opts.split, "split", "", `split input into "line", "paragraph", "srt" (SubRip subtitle) or "markdown" segments and translate each segment preserving the structure (default: srt for -i *.srt, otherwise translate whole input at once)`)
+	flag.BoolVar(&opts.tsv, "tsv", false, `write "original<TAB>translation" lines with tabs, newlines and backslashes escaped. With -split, a line is written for each segment. With multiple target languages, a column is written for each language`)
+	flag.BoolVar(&opts.showOriginal, "show-original", false, "write original text along with translated text labeled with their languages")
+	flag.BoolVar(&opts.copy, "copy", false, "copy translated text to the clipboard in addition to writing it. It can be used with -open")
+	flag.BoolVar(&opts.version, "version", false, "print version and exit")
+	flag.BoolVar(&opts.languages, "languages", false, "print bundled list of common language codes and names and exit. It works offline unlike -list-languages, which asks the backend for supported languages. It is a flag rather than a subcommand since \"gtrans languages\" translates the word")
+	flag.StringVar(&opts.completion, "completion", "", "print completion script for shell (bash, zsh or fish)")
+	flag.BoolVar(&opts.verbose, "v", false, "shorthand for -verbose")
+	flag.BoolVar(&opts.verbose, "verbose", false, "write the source language and its confidence to STDERR")
+	flag.StringVar(&opts.format, "format", "text", "format of input text (text or html). HTML tags are preserved with html")
+	flag.BoolVar(&opts.htmlToText, "html-to-text", false, "translate input as HTML (implies -format html) and write plain text of translations with tags stripped")
+	flag.StringVar(&opts.model, "model", "", "translation model (nmt or base) (default: chosen by the API)")
+	flag.BoolVar(&opts.skipSame, "skip-same", false, "write input already in the target language as it is without translating it. Each argument of -separate or segment of -split is checked by its own detected language")
+	flag.BoolVar(&opts.roundTrip, "roundtrip", false, "translate the result back into the source language to verify the translation")
+	flag.IntVar(&opts.concurrency, "j", 1, "shorthand for -concurrency")
+	flag.IntVar(&opts.concurrency, "concurrency", 1, "number of concurrent requests. With more than 1, each argument of -separate or segment of -split is translated in its own request. With -batch-file, it's the number of files translated at a time")
+	flag.BoolVar(&opts.showStats, "stats", false, "write the number of inputs, billable characters, API calls and elapsed time to STDERR at exit. Cached translations are not counted")
+	flag.Float64Var(&opts.rps, "rps", 0, "max number of API requests per second. Requests wait for the limit. 0 means no limit")
+	flag.IntVar(&opts.charsPerMin, "chars-per-min", 0, "max number of characters sent to the API per minute. Requests wait for the limit. 0 means no limit")
+	flag.BoolVar(&opts.showProgress, "progress", false, "write the number of translated texts (arguments of -separate or segments of -split) to STDERR while translating")
+	flag.StringVar(&opts.backend, "backend", "google", "translation backend (google, deepl, libretranslate or azure)")
+	flag.StringVar(&opts.inputFile, "i", "", "read input text from the file instead of STDIN")
+	flag.StringVar(&opts.docDelimiter, "doc-delimiter", "", `treat input as multiple documents separated by lines of this delimiter (e.g. "---") and translate each document independently in one request. Translations are written with the same delimiter`)
+	flag.StringVar(&opts.separator, "separator", `\n`, `separator between results of multiple inputs (e.g. -separate) or target languages. Escape sequences such as \n and \t are interpreted`)
+	flag.StringVar(&opts.color, "color", "auto", "color original and translated text of -show-original and -roundtrip: auto, always or never. auto colors output to a terminal unless NO_COLOR is set")
+	flag.StringVar(&opts.rtl, "rtl", "auto", "wrap lines of translations into right-to-left languages such as Arabic and Hebrew with Unicode directional marks to display them correctly: auto, always or never. auto wraps output to a terminal")
+	flag.StringVar(&opts.inEncoding, "input-encoding", "utf-8", "character encoding of input such as shift_jis, euc-jp and gbk. Input is decoded to UTF-8 before translation")
+	flag.StringVar(&opts.outEncoding, "output-encoding", "utf-8", "character encoding of output such as shift_jis, euc-jp and gbk. Characters which the encoding can't represent are replaced")
+	flag.StringVar(&opts.outputFile, "o", "", "write the result to the file instead of STDOUT. The file is truncated if it exists")
+	flag.StringVar(&opts.batchFile, "batch-file", "", "translate files listed in the file. Each line is an input file optionally followed by a target language and an output file (default: <input>.<lang><ext> next to the input). Files are translated with -concurrency workers and failed files are reported to STDERR (all files with -verbose)")
+	flag.BoolVar(&opts.appendOutput, "append", false, "append the result to the file of -o instead of truncating it")
+	flag.BoolVar(&opts.preserve, "preserve", false, "keep URLs, email addresses, format verbs (e.g. %s) and placeholders (e.g. {0}) untranslated")
+	flag.BoolVar(&opts.keep, "keep", false, "keep text between delimiters of -keep-delimiter (e.g. <keep>gtrans</keep>) untranslated. The delimiters are removed")
+	flag.StringVar(&opts.keepDelimiter, "keep-delimiter", "<keep>,</keep>", "opening and closing delimiters of -keep separated by a comma, or a delimiter used for both (e.g. `)")
+	flag.Var(&opts.preservePats, "preserve-pattern", "regular expression of additional tokens to keep untranslated. It can be given multiple times and implies -preserve")
+	flag.BoolVar(&opts.noCache, "no-cache", false, "don't use the translation cache in $XDG_CONFIG_HOME/gtrans/cache")
+	flag.BoolVar(&opts.clearCache, "clear-cache", false, "remove the translation cache and exit")
+	flag.BoolVar(&opts.noNewline, "n", false, "shorthand for -no-newline")
+	flag.BoolVar(&opts.noNewline, "no-newline", false, "don't write the trailing newline after the translated text. Multiple results are still separated by -separator")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "print the number of billable characters and estimated cost instead of calling the API")
+	flag.BoolVar(&opts.countOnly, "count-only", false, "print only the number of texts translated after splitting and chunking instead of translations. With -dry-run, the API is not called")
+	flag.StringVar(&opts.langMap, "lang-map", "", "file mapping glob patterns of -i file or files of -batch-file to target languages (\"<pattern> <lang>\" per line). -to is used for files which match nothing")
+	flag.StringVar(&opts.glossary, "glossary", "", "glossary ID or resource name (projects/<project>/locations/<location>/glossaries/<id>) to translate with Cloud Translation Advanced (v3) API")
+	flag.StringVar(&opts.project, "project", "", "Google Cloud project of -glossary (default: $GOOGLE_CLOUD_PROJECT)")
+	flag.StringVar(&opts.location, "location", "", "location of -glossary (default: "+gtrans.DefaultLocation+")")
+	flag.BoolVar(&opts.csv, "csv", false, "treat input as CSV and translate cells of -column preserving the other columns")
+	flag.IntVar(&opts.column, "column", 1, "column number (starting from 1) to translate with -csv")
+	flag.StringVar(&opts.csvDelimiter, "csv-delimiter", "", `field delimiter of -csv. "tab" for TSV (default: tab for -i *.tsv, otherwise ",")`)
+	flag.BoolVar(&opts.csvHeader, "csv-header", false, "translate the header (first) row as well with -csv")
+	flag.BoolVar(&opts.quiet, "quiet", false, "don't write warnings and -verbose messages to STDERR. Errors are still written")
+	flag.BoolVar(&opts.explain, "explain", false, "write parameters of each API request (target, source, format, model and input length) and metadata of the response to STDERR for debugging. The API key is masked")
+	flag.StringVar(&opts.logLevel, "log-level", "warn", "level of diagnostic messages written to STDERR: error, warn, info (e.g. retries and rate limit waits) or debug (e.g. cache hits and request timings). -verbose implies info and -quiet implies error")
+	flag.StringVar(&opts.logFile, "log", "", "append diagnostic logs of requests, retries and timings to the file")
+	flag.BoolVar(&opts.preserveSpace, "preserve-space", false, "keep leading and trailing whitespace such as indentation of input and translate only the rest. It's always done for segments of -split")
+	flag.BoolVar(&opts.nfc, "nfc", false, "normalize input text to Unicode NFC before translation")
+	flag.Float64Var(&opts.failConf, "fail-on-low-confidence", 0, "fail without writing translations if confidence of the detected source language is lower than this value (0 to 1). 0 disables the check")
+	flag.IntVar(&opts.detectSample, "detect-sample", gtrans.DefaultDetectionSample, "max number of characters of each input sent to detect its language. The whole input is still translated. 0 sends the whole input")
+	flag.Float64Var(&opts.minConfidence, "min-confidence", 0, "switch target language to the second language only if confidence of the detected language is at least this value (0 to 1)")
+	flag.BoolVar(&opts.url, "url", false, "treat input as URLs and translate visible text of the pages")
+	flag.BoolVar(&opts.romanize, "romanize", false, "also write romanized reading of translated text (Cyrillic, Greek, Kana and Hangul)")
+	flag.BoolVar(&opts.watch, "watch", false, "watch the clipboard and translate its text whenever it changes until interrupted")
+	flag.DurationVar(&opts.watchInterval, "watch-interval", 500*time.Millisecond, "interval of polling the clipboard with -watch")
+	flag.BoolVar(&opts.notify, "notify", false, "send a desktop notification of translated text. With -open, the text is translated for the notification as well")
+	flag.BoolVar(&opts.swap, "swap", false, "translate from the target language into the detected source language of the last translation, e.g. to reply in the original language. The source language is saved in $XDG_CONFIG_HOME/gtrans/last_source (~/.config/gtrans/last_source) by each run without -from, -batch-file or -concurrency")
+	flag.IntVar(&opts.alternatives, "alternatives", 0, "write up to the number of alternative translations indented under each translation. Only -backend libretranslate supports it")
+	flag.BoolVar(&opts.outLangNames, "output-lang-names", false, "write language names (e.g. Japanese) instead of codes in output of -verbose, -detect, -brief and -show-original. Names are written in the target language, or English with -detect")
+	flag.BoolVar(&opts.brief, "brief", false, `write "source->target: translation" (or "lang (confidence): input" with -detect) in one line per input`)
+	flag.BoolVar(&opts.stdinLines, "stdin-lines", false, "translate each line of STDIN and write the translation as soon as the line is read, e.g. in a long-running pipeline")
+	flag.BoolVar(&opts.interactive, "interactive", false, "read and translate STDIN line by line interactively. Type :help for commands")
+	flag.StringVar(&opts.endpoint, "endpoint", "", "Google Translate API endpoint such as a regional endpoint (default: $GOOGLE_TRANSLATE_ENDPOINT or https://translation.googleapis.com/language/translate/)")
+	flag.StringVar(&opts.userAgent, "user-agent", "", "User-Agent of API requests (default: $GTRANS_USER_AGENT or gtrans/<version>)")
+	flag.StringVar(&opts.apiKey, "api-key", "", "Google Translate API key. It takes precedence over -key-file and $GOOGLE_TRANSLATE_API_KEY")
+	flag.BoolVar(&opts.configDump, "config-dump", false, "write the effective configuration and the source of each value (flag, env, file or default) to STDERR and exit. Secret values are masked")
+	flag.StringVar(&opts.keyFile, "key-file", "", "file containing Google Translate API key (default: $GOOGLE_TRANSLATE_API_KEY_FILE)")
+}
+
+// usage writes the usage message and flags to w.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "%s\n", usageMessage)
+	fmt.Fprintln(w, "Flags:")
+	flag.CommandLine.SetOutput(w)
+	flag.PrintDefaults()
+}
+
+func main() {
+	// Handle errors by ourselves to exit with 0 for -h and -help.
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	flag.Usage = func() {}
+	switch err := flag.CommandLine.Parse(os.Args[1:]); {
+	case errors.Is(err, flag.ErrHelp):
+		usage(os.Stdout)
+		os.Exit(0)
+	case err != nil:
+		// The error is already written by flag package.
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	// Cancel in-flight requests on interruption.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// Restore the default behavior so that next signal terminates gtrans
+		// even if it's blocked on reading STDIN.
+		stop()
+	}()
+	if err := Main(ctx, os.Stdin, os.Stdout, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		var cerr *configError
+		switch {
+		case ctx.Err() != nil:
+			os.Exit(130)
+		case errors.As(err, &cerr):
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+// maskedError is an error whose message has API keys masked.
+type maskedError struct {
+	err     error
+	secrets []string
+}
+
+func (e *maskedError) Error() string { return maskSecrets(e.err.Error(), e.secrets) }
+
+func (e *maskedError) Unwrap() error { return e.err }
+
+// maskSecrets returns s with secrets such as API keys masked not to leak
+// them in errors and logs.
+func maskSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, "<masked>")
+		}
+	}
+	return s
+}
+
+// configError represents an error of usage or configuration such as invalid
+// flags or missing API key, as opposed to runtime errors. gtrans exits with
+// status 2 on it.
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+
+func configErrorf(format string, a ...interface{}) error {
+	return &configError{err: fmt.Errorf(format, a...)}
+}
+
+func Main(ctx context.Context, r io.Reader, w io.Writer, opts options) (err error) {
+	if opts.batchFile != "" && (flag.NArg() > 0 || opts.inputFile != "" || opts.outputFile != "") {
+		return configErrorf("-batch-file reads input files from the list and cannot be used with arguments, -i or -o")
+	}
+	if opts.inputFile != "" {
+		f, err := os.Open(opts.inputFile)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	if opts.appendOutput && opts.outputFile == "" {
+		return configErrorf("-append requires -o and cannot be used with STDOUT")
+	}
+	sep, serr := unescape(opts.separator)
+	if serr != nil {
+		return configErrorf("invalid -separator value %q: bad escape sequence", opts.separator)
+	}
+	opts.separator = sep
+	inEnc, err := lookupEncoding(opts.inEncoding)
+	if err != nil {
+		return configErrorf("invalid -input-encoding: %v", err)
+	}
+	if inEnc != nil {
+		r = transform.NewReader(r, inEnc.NewDecoder())
+	}
+	outEnc, err := lookupEncoding(opts.outEncoding)
+	if err != nil {
+		return configErrorf("invalid -output-encoding: %v", err)
+	}
+
+	if opts.version || opts.languages || opts.completion != "" {
+		return writeInfo(w, opts, outEnc)
+	}
+	if opts.clearCache {
+		dir, err := cacheDir()
+		if err != nil {
+			return err
+		}
+		return os.RemoveAll(dir)
+	}
+
+	// -inline-target doesn't override -to and -primary.
+	explicitTarget := opts.targetLang != "" || opts.primaryLang != ""
+	if opts.primaryLang != "" {
+		if opts.targetLang != "" {
+			return configErrorf("-primary cannot be used with -to")
+		}
+		if strings.Contains(opts.primaryLang, ",") {
+			return configErrorf("-primary must be a single language: %q", opts.primaryLang)
+		}
+		opts.targetLang = opts.primaryLang
+	}
+
+	if opts.detectAll {
+		opts.detect = true
+	}
+
+	cfg, path, err := loadConfig()
+	if err != nil {
+		return &configError{err: err}
+	}
+	opts.settings = resolveSettings(&opts, cfg, path)
+	if opts.configDump {
+		return writeSettings(os.Stderr, opts.settings)
+	}
+
+	level, err := parseLogLevel(opts.logLevel)
+	if err != nil {
+		return &configError{err: err}
+	}
+	if opts.quiet {
+		opts.verbose = false
+		level = levelError
+	} else if opts.verbose && level < levelInfo {
+		level = levelInfo
+	}
+	opts.log = &leveledLogger{w: os.Stderr, level: level, secrets: secrets(opts)}
+	gtrans.Log = opts.log
+	if opts.logFile != "" {
+		f, ferr := os.OpenFile(opts.logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if ferr != nil {
+			return fmt.Errorf("failed to open log file: %v", ferr)
+		}
+		defer f.Close()
+		opts.logger = log.New(f, "gtrans: ", log.LstdFlags)
+	} else if opts.log.enabled(levelDebug) {
+		// Requests and timings of -log are debug messages otherwise.
+		opts.logger = log.New(levelWriter{l: opts.log, level: levelDebug}, "", 0)
+	}
+	if opts.logger != nil {
+		start := time.Now()
+		args := make([]string, len(os.Args))
+		for i, arg := range os.Args {
+			args[i] = maskSecrets(arg, opts.log.secrets)
+		}
+		opts.logger.Printf("start: %q", args)
+		defer func() {
+			if err != nil {
+				opts.logger.Printf("error: %v", err)
+			}
+			opts.logger.Printf("done in %v", time.Since(start).Round(time.Millisecond))
+		}()
+	}
+	defer func() {
+		// Errors such as *url.Error may contain the API key in the URL.
+		if err != nil && maskSecrets(err.Error(), opts.log.secrets) != err.Error() {
+			err = &maskedError{err: err, secrets: opts.log.secrets}
+		}
+	}()
+
+	if opts.secondaryLang != "" {
+		opts.secondLang, err = resolveLang(opts.secondaryLang)
+		if err != nil {
+			return configErrorf("invalid -secondary language: %v", err)
+		}
+	}
+
+	if opts.sourceLang != "" {
+		opts.sourceLang, err = resolveLang(opts.sourceLang)
+		if err != nil {
+			return configErrorf("invalid source language: %v", err)
+		}
+	}
+
+	if opts.htmlToText {
+		opts.format = string(translate.HTML)
+	}
+	switch translate.Format(opts.format) {
+	case translate.Text, translate.HTML:
+	default:
+		return configErrorf("invalid -format value %q: must be text or html", opts.format)
+	}
+
+	if opts.failConf < 0 || opts.failConf > 1 {
+		return configErrorf("invalid -fail-on-low-confidence value %v: must be between 0 and 1", opts.failConf)
+	}
+	if opts.detectSample < 0 {
+		return configErrorf("invalid -detect-sample value %d: must not be negative", opts.detectSample)
+	}
+	if opts.minConfidence < 0 || opts.minConfidence > 1 {
+		return configErrorf("invalid -min-confidence value %v: must be between 0 and 1", opts.minConfidence)
+	}
+
+	if opts.concurrency < 1 {
+		return configErrorf("invalid -concurrency value %d: must be positive", opts.concurrency)
+	}
+	if opts.rps < 0 || opts.charsPerMin < 0 {
+		return configErrorf("-rps and -chars-per-min must not be negative")
+	}
+
+	switch opts.model {
+	case "", "nmt", "base":
+	default:
+		return configErrorf("invalid -model value %q: must be nmt or base", opts.model)
+	}
+
+	for _, p := range opts.preservePats {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return configErrorf("invalid -preserve-pattern %q: %v", p, err)
+		}
+		opts.preserveRegexps = append(opts.preserveRegexps, re)
+	}
+	if opts.keep {
+		delims := strings.Split(opts.keepDelimiter, ",")
+		if len(delims) == 1 {
+			delims = append(delims, delims[0])
+		}
+		if len(delims) != 2 || delims[0] == "" || delims[1] == "" {
+			return configErrorf("invalid -keep-delimiter %q: must be <open>,<close> or a delimiter for both", opts.keepDelimiter)
+		}
+		opts.keepOpen, opts.keepClose = delims[0], delims[1]
+	}
+
+	if opts.csv {
+		if opts.column < 1 {
+			return configErrorf("invalid -column value %d: must be positive", opts.column)
+		}
+		if _, err := csvDelimiter(opts); err != nil {
+			return &configError{err: err}
+		}
+	}
+
+	if opts.markdown {
+		if opts.split != "" && opts.split != "markdown" {
+			return configErrorf("-markdown can't be used with -split %s", opts.split)
+		}
+		opts.split = "markdown"
+	}
+	if opts.split == "markdown" && opts.format == "html" {
+		return configErrorf("markdown can't be translated with -format html")
+	}
+	if opts.split == "" && strings.EqualFold(filepath.Ext(opts.inputFile), ".srt") {
+		opts.split = "srt"
+	}
+	if _, ok := splitFuncs[opts.split]; !ok {
+		return configErrorf("invalid -split value %q: must be line, paragraph, srt or markdown", opts.split)
+	}
+
+	if opts.tsv && (opts.jsonOutput || opts.csv) {
+		return configErrorf("-tsv cannot be used with -json or -csv")
+	}
+
+	if opts.endpoint != "" {
+		opts.endpoint, err = normalizeEndpoint(opts.endpoint)
+		if err != nil {
+			return &configError{err: err}
+		}
+	}
+
+	if strings.ContainsAny(opts.userAgent, "\r\n") {
+		return configErrorf("invalid -user-agent %q: must not contain newlines", opts.userAgent)
+	}
+	gtrans.UserAgent = opts.userAgent
+
+	// Interactive, watch, stdin-lines and batch modes read inputs by
+	// themselves.
+	batch := opts.batchFile != ""
+	session := opts.interactive || opts.watch || opts.stdinLines || batch
+	if (opts.interactive && opts.watch) || (opts.stdinLines && (opts.interactive || opts.watch)) || (batch && (opts.interactive || opts.watch || opts.stdinLines)) {
+		return configErrorf("-interactive, -watch, -stdin-lines and -batch-file cannot be used together")
+	}
+	if session && (opts.listLanguages || opts.url) {
+		return configErrorf("-interactive, -watch, -stdin-lines and -batch-file cannot be used with -list-languages or -url")
+	}
+	if batch && (opts.detect || opts.csv || opts.countOnly) {
+		return configErrorf("-batch-file cannot be used with -detect, -csv or -count-only")
+	}
+	if opts.docDelimiter != "" && (opts.stdinLines || opts.separate || opts.csv) {
+		return configErrorf("-doc-delimiter cannot be used with -stdin-lines, -separate or -csv")
+	}
+	if opts.stdinLines && flag.NArg() > 0 {
+		return configErrorf("-stdin-lines reads input from STDIN or -i and doesn't accept arguments")
+	}
+	if opts.watch && opts.watchInterval <= 0 {
+		return configErrorf("invalid -watch-interval value %v: must be positive", opts.watchInterval)
+	}
+
+	var inputs []string
+	if !opts.listLanguages && !session {
+		inputs, err = readInputs(r, flag.Args(), opts.separate)
+		if err != nil {
+			return err
+		}
+		if opts.docDelimiter != "" {
+			// Documents are translated as separate inputs in one request
+			// and written with the same delimiter.
+			inputs = splitDocuments(strings.Join(inputs, "\n"), opts.docDelimiter)
+			if opts.separator == "\n" {
+				opts.separator = "\n" + opts.docDelimiter + "\n"
+			}
+		}
+		if opts.inlineTarget {
+			// The directive is stripped even with -to not to translate it.
+			if lang := stripInlineTarget(inputs); lang != "" && !explicitTarget {
+				opts.targetLang = lang
+			}
+		}
+		if isBlank(inputs) {
+			// Nothing to translate. Don't waste API quota.
+			return nil
+		}
+		if opts.nfc {
+			for i, input := range inputs {
+				inputs[i] = norm.NFC.String(input)
+			}
+		}
+		if opts.url {
+			inputs, err = parseURLs(inputs)
+			if err != nil {
+				return &configError{err: err}
+			}
+		} else if err := checkMaxChars(opts, inputs); err != nil {
+			return err
+		}
+	}
+
+	var langMap []langMapEntry
+	if opts.langMap != "" {
+		if opts.inputFile == "" && !batch {
+			return configErrorf("-lang-map requires -i or -batch-file")
+		}
+		langMap, err = loadLangMap(opts.langMap)
+		if err != nil {
+			return &configError{err: err}
+		}
+		if lang, ok := matchLangMap(langMap, opts.inputFile); ok && !batch {
+			opts.targetLang = lang
+		}
+	}
+
+	// Target language is not necessary for detection.
+	if opts.targetLang == "" && (!opts.detect || opts.listLanguages) {
+		opts.targetLang, err = gtrans.DefaultTargetLang()
+		if err != nil {
+			return &configError{err: err}
+		}
+	}
+
+	targetLangs := strings.Split(opts.targetLang, ",")
+	if !opts.detect {
+		for i, lang := range targetLangs {
+			targetLangs[i], err = resolveLang(lang)
+			if err != nil {
+				return configErrorf("invalid target language: %v", err)
+			}
+		}
+	}
+
+	var jobs []batchJob
+	if batch {
+		jobs, err = loadBatchFile(opts.batchFile, targetLangs, langMap)
+		if err != nil {
+			return &configError{err: err}
+		}
+	}
+
+	if opts.swap {
+		if opts.sourceLang != "" || opts.detect || opts.listLanguages || session || len(targetLangs) > 1 {
+			return configErrorf("-swap cannot be used with -from, -detect, -list-languages, -interactive, -watch, -stdin-lines, -batch-file or multiple target languages")
+		}
+		last, err := loadLastSource()
+		if err != nil {
+			return &configError{err: err}
+		}
+		opts.sourceLang = targetLangs[0]
+		targetLangs = []string{last}
+		// The direction isn't obvious from the command. Write it with
+		// -verbose or -log-level info.
+		opts.log.Infof("swap: translate from %s to %s", opts.sourceLang, last)
+	}
+
+	if opts.countOnly && (opts.detect || opts.listLanguages || opts.csv || opts.doOpenBrowser || session) {
+		return configErrorf("-count-only cannot be used with -detect, -list-languages, -csv, -open, -interactive, -watch, -stdin-lines or -batch-file")
+	}
+
+	// The output file is opened after validating flags and inputs so that
+	// errors don't truncate it.
+	w, closeOutput, err := openOutput(&opts, w, outEnc)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeOutput(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	if opts.doOpenBrowser && !opts.listLanguages && !opts.detect && !session {
+		err := openGoogleTranslate(w, opts, opts.sourceLang, targetLangs[0], strings.Join(inputs, " "))
+		if err != nil || (!opts.notify && !opts.copy) {
+			return err
+		}
+		// Translate text only for the notification and -copy.
+		w = ioutil.Discard
+	}
+
+	if opts.dryRun && !opts.listLanguages && !session {
+		if opts.url {
+			// Fetching pages is necessary to count characters.
+			inputs, err = fetchInputs(ctx, inputs, opts.separate)
+			if err != nil {
+				return err
+			}
+			if err := checkMaxChars(opts, inputs); err != nil {
+				return err
+			}
+		}
+		return runDryRun(ctx, w, opts, targetLangs, inputs)
+	}
+
+	if opts.showStats {
+		opts.stats = &stats{start: time.Now()}
+		defer opts.stats.write(os.Stderr)
+	}
+
+	if !batch && opts.concurrency == 1 {
+		opts.lastSource = &lastSource{}
+		defer func() {
+			if serr := opts.lastSource.save(); serr != nil {
+				warnf(opts, "failed to save source language for -swap: %v", serr)
+			}
+		}()
+	}
+
+	client, err := newClient(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if opts.outLangNames && !opts.listLanguages {
+		display := "en"
+		if !opts.detect {
+			display = targetLangs[0]
+		}
+		opts.langNames, err = loadLangNames(ctx, client, display)
+		if err != nil {
+			// Language codes are still useful.
+			warnf(opts, "failed to load language names: %v", err)
+		}
+	}
+	if opts.validate && !opts.listLanguages {
+		if err := validateLangs(ctx, client, opts, targetLangs); err != nil {
+			return authError(err, opts)
+		}
+	}
+	if opts.alternatives > 0 && !gtrans.SupportsAlternatives(client) {
+		return configErrorf("-alternatives is not supported by -backend %s. Use -backend libretranslate", opts.backend)
+	}
+
+	switch {
+	case opts.interactive:
+		return runInteractive(ctx, r, w, client, opts, targetLangs)
+	case opts.watch:
+		return runWatch(ctx, w, client, opts, targetLangs)
+	case opts.stdinLines:
+		return runLines(ctx, r, w, client, opts, targetLangs)
+	case batch:
+		return runBatch(ctx, client, opts, jobs)
+	}
+
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	if opts.url {
+		inputs, err = fetchInputs(ctx, inputs, opts.separate)
+		if err != nil {
+			return contextError(ctx, err, opts.timeout)
+		}
+		if err := checkMaxChars(opts, inputs); err != nil {
+			return err
+		}
+	}
+
+	if opts.showProgress {
+		opts.progress = newProgress(os.Stderr)
+		defer opts.progress.finish()
+	}
+
+	switch {
+	case opts.listLanguages:
+		err = listLanguages(ctx, w, client, targetLangs[0])
+	case opts.detect:
+		err = runDetection(ctx, w, client, opts, inputs)
+	case opts.csv:
+		if len(targetLangs) > 1 {
+			return configErrorf("-csv doesn't support multiple target languages")
+		}
+		err = runCSV(ctx, w, client, opts, targetLangs[0], strings.Join(inputs, "\n"))
+	case opts.countOnly:
+		err = runCount(ctx, w, client, opts, targetLangs, inputs)
+	default:
+		err = runTranslation(ctx, w, client, opts, targetLangs, inputs)
+	}
+	return authError(contextError(ctx, err, opts.timeout), opts)
+}
+
+// checkMaxChars returns an error if inputs have more characters than
+// -max-chars in total, which the API would reject. Inputs are not checked
+// with -chunk since they are split into requests within the limit.
+func checkMaxChars(opts options, inputs []string) error {
+	if opts.chunk || opts.maxChars <= 0 {
+		return nil
+	}
+	if n := countChars(inputs); n > opts.maxChars {
+		return fmt.Errorf("input has %d characters, more than -max-chars %d. Use -chunk to split it into requests or split the input into smaller pieces", n, opts.maxChars)
+	}
+	return nil
+}
+
+// resolveLang returns the language code of lang, which is a language code
+// or a language name such as "Japanese". Aliases of language codes such as
+// "iw" and "jp" are normalized.
+func resolveLang(lang string) (string, error) {
+	lang = gtrans.NormalizeLangCode(lang)
+	if _, err := language.Parse(lang); err == nil {
+		return lang, nil
+	}
+	if code := gtrans.LangCodeFromName(lang); code != "" {
+		return code, nil
+	}
+	return "", fmt.Errorf("%q is neither a language code nor a known language name", lang)
+}
+
+// authError returns a friendly error if err is an authentication or
+// authorization error of Google Translate API such as invalid API key. The
+// original error is included with -verbose.
+func authError(err error, opts options) error {
+	if opts.backend != "google" {
+		return err
+	}
+	if opts.glossary != "" {
+		if s, ok := status.FromError(err); ok && (s.Code() == codes.PermissionDenied || s.Code() == codes.Unauthenticated) {
+			return configErrorf("Google Cloud credentials lack access to Cloud Translation Advanced (v3) API or -glossary. Check that the API is enabled for the project and the credentials have roles/cloudtranslate.user: %v", err)
+		}
+		return err
+	}
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return err
+	}
+	switch {
+	case gerr.Code == http.StatusUnauthorized, gerr.Code == http.StatusForbidden:
+	case gerr.Code == http.StatusBadRequest && isKeyInvalid(gerr):
+	default:
+		return err
+	}
+	msg := "invalid or unauthorized GOOGLE_TRANSLATE_API_KEY"
+	if opts.apiKey != "" {
+		msg = "invalid or unauthorized -api-key"
+	}
+	if key, _ := apiKey(opts); key == "" {
+		msg = "unauthorized Google Cloud credentials"
+	}
+	msg += ". Check it and that Cloud Translation API is enabled for the project"
+	if !opts.verbose {
+		return configErrorf("%s (-verbose shows the original error)", msg)
+	}
+	return configErrorf("%s: %w", msg, err)
+}
+
+func isKeyInvalid(err *googleapi.Error) bool {
+	for _, e := range err.Errors {
+		if e.Reason == "keyInvalid" {
+			return true
+		}
+	}
+	return strings.Contains(err.Message, "API key not valid")
+}
+
+// contextError returns an error describing why ctx is done if err is caused
+// by that.
+func contextError(ctx context.Context, err error, timeout time.Duration) error {
+	if err != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			return fmt.Errorf("request timed out after %v", timeout)
+		case context.Canceled:
+			return errors.New("interrupted")
+		}
+	}
+	return err
+}
+
+// newClient returns a client of the backend selected by -backend.
+func newClient(ctx context.Context, opts options) (gtrans.Translator, error) {
+	var client gtrans.Translator
+	switch opts.backend {
+	case "google":
+		if opts.glossary != "" {
+			if opts.project == "" && !strings.HasPrefix(opts.glossary, "projects/") {
+				return nil, configErrorf("-glossary requires -project or GOOGLE_CLOUD_PROJECT")
+			}
+			var err error
+			client, err = gtrans.NewAdvancedClient(ctx, opts.project, opts.location, opts.glossary)
+			if err != nil {
+				return nil, &configError{err: err}
+			}
+			break
+		}
+		key, err := apiKey(opts)
+		if err != nil {
+			return nil, &configError{err: err}
+		}
+		var copts []option.ClientOption
+		if opts.endpoint != "" {
+			copts = append(copts, option.WithEndpoint(opts.endpoint))
+		}
+		client, err = gtrans.NewClient(ctx, key, copts...)
+		if err != nil {
+			return nil, &configError{err: err}
+		}
+	case "deepl":
+		var err error
+		client, err = gtrans.NewDeepLClient(opts.settings.deeplKey.value)
+		if err != nil {
+			return nil, &configError{err: err}
+		}
+	case "libretranslate":
+		var err error
+		client, err = gtrans.NewLibreTranslateClient(opts.settings.libreURL.value, opts.settings.libreKey.value)
+		if err != nil {
+			return nil, &configError{err: err}
+		}
+	case "azure":
+		var err error
+		client, err = gtrans.NewAzureClient(opts.settings.azureKey.value, opts.settings.azureRegion.value, opts.settings.azureEndpoint.value)
+		if err != nil {
+			return nil, &configError{err: err}
+		}
+	default:
+		return nil, configErrorf("invalid -backend value %q: must be google, deepl, libretranslate or azure", opts.backend)
+	}
+	if opts.explain {
+		client = &explainClient{Translator: client, w: os.Stderr, backend: opts.backend, secrets: secrets(opts)}
+	}
+	if opts.logger != nil {
+		client = &logClient{Translator: client, logger: opts.logger, secrets: secrets(opts)}
+	}
+	if opts.stats != nil {
+		client = &statsClient{Translator: client, stats: opts.stats}
+	}
+	if opts.rps > 0 || opts.charsPerMin > 0 {
+		client = newRateLimitClient(client, opts.rps, opts.charsPerMin, opts.log)
+	}
+	client = gtrans.NewRetryClient(client, opts.retries)
+	if !opts.noCache {
+		if dir, err := cacheDir(); err == nil {
+			client = gtrans.NewCacheClient(client, dir, cacheNamespace(opts))
+		}
+	}
+	return client, nil
+}
+
+// cacheNamespace returns the namespace of the translation cache. Translations
+// with a glossary are cached separately for each glossary since the glossary
+// changes translations of the same input.
+func cacheNamespace(opts options) string {
+	if opts.backend != "google" || opts.glossary == "" {
+		return opts.backend
+	}
+	if strings.HasPrefix(opts.glossary, "projects/") {
+		// The resource name contains the project and location.
+		return opts.backend + "/" + opts.glossary
+	}
+	return fmt.Sprintf("%s/projects/%s/locations/%s/glossaries/%s", opts.backend, opts.project, opts.location, opts.glossary)
+}
+
+// normalizeEndpoint validates endpoint URL and returns it with trailing slash,
+// which is required by the API client to resolve paths.
+func normalizeEndpoint(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid endpoint %q: must be an http or https URL (e.g. https://translation.googleapis.com/language/translate/)", endpoint)
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return endpoint, nil
+}
+
+// apiKey returns Google Translate API key. It uses -api-key if given.
+// Otherwise, it reads the key from -key-file or
+// $GOOGLE_TRANSLATE_API_KEY_FILE if set, otherwise uses
+// $GOOGLE_TRANSLATE_API_KEY or api_key in the config file.
+func apiKey(opts options) (string, error) {
+	key, keyFile := opts.settings.apiKey, opts.settings.keyFile
+	if key.source != sourceFlag && keyFile.value != "" {
+		b, err := ioutil.ReadFile(keyFile.value)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key file: %v", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return key.value, nil
+}
+
+// createOutputFile creates the output file of -o. If appendOutput is true, it
+// opens the file to append instead and writes a newline first if the file
+// doesn't end with one so that appended results are newline-separated.
+func createOutputFile(name string, appendOutput bool) (*os.File, error) {
+	if !appendOutput {
+		f, err := os.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %v", err)
+		}
+		return f, nil
+	}
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %v", err)
+	}
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, fi.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte("\n")); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("failed to write output file: %v", err)
+			}
+		}
+	}
+	return f, nil
+}
+
+// openOutput returns the writer of the result, which is the -o file if it's
+// given or w otherwise, encoded with outEnc if it's not nil. It also sets
+// whether to color the output and add RTL marks to opts. The returned function
+// flushes and closes the writer.
+func openOutput(opts *options, w io.Writer, outEnc encoding.Encoding) (io.Writer, func() error, error) {
+	var closers []func() error
+	closeAll := func() error {
+		var err error
+		for i := len(closers) - 1; i >= 0; i-- {
+			if cerr := closers[i](); err == nil && cerr != nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+	if opts.outputFile != "" {
+		f, err := createOutputFile(opts.outputFile, opts.appendOutput)
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, func() error {
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write output file: %v", err)
+			}
+			return nil
+		})
+		w = f
+	}
+	var err error
+	if opts.colored, err = useColor(opts.color, w); err != nil {
+		closeAll()
+		return nil, nil, &configError{err: err}
+	}
+	if opts.rtlMarks, err = useRTLMarks(opts.rtl, w); err != nil {
+		closeAll()
+		return nil, nil, &configError{err: err}
+	}
+	// Output is encoded after checking whether w is a terminal.
+	if outEnc != nil {
+		// Characters which the encoding can't represent are replaced.
+		tw := transform.NewWriter(w, encoding.ReplaceUnsupported(outEnc.NewEncoder()))
+		closers = append(closers, func() error {
+			if err := tw.Close(); err != nil {
+				return fmt.Errorf("failed to encode output: %v", err)
+			}
+			return nil
+		})
+		w = tw
+	}
+	return w, closeAll, nil
+}
+
+// writeInfo writes the output of -version, -languages or -completion to w or
+// the -o file.
+func writeInfo(w io.Writer, opts options, outEnc encoding.Encoding) (err error) {
+	if opts.completion != "" {
+		// Don't truncate the output file for an unsupported shell.
+		if err := checkShell(opts.completion); err != nil {
+			return &configError{err: err}
+		}
+	}
+	w, closeOutput, err := openOutput(&opts, w, outEnc)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeOutput(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	switch {
+	case opts.version:
+		writeVersion(w)
+		return nil
+	case opts.languages:
+		return writeLanguages(w)
+	}
+	return writeCompletion(w, opts.completion)
+}
+
+// utf8BOM is the byte order mark of UTF-8.
+const utf8BOM = "\ufeff"
+
+// readInputs returns texts to translate. It reads from r if no arguments are
+// given. If separate is true, each argument is treated as its own input.
+// Leading BOM of r is removed.
+func readInputs(r io.Reader, args []string, separate bool) ([]string, error) {
+	if separate && len(args) > 0 {
+		return args, nil
+	}
+	text := strings.Join(args, " ")
+	if text == "" {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		// Files created on Windows often start with UTF-8 BOM.
+		text = strings.TrimPrefix(string(b), utf8BOM)
+	}
+	return []string{text}, nil
+}
+
+// openGoogleTranslate opens Google Translate of text in the browser. If the
+// URL is longer than -max-url-length, which browsers may truncate, it opens
+// Google Translate without text and copies text to the clipboard instead so
+// that users can paste it.
+func openGoogleTranslate(w io.Writer, opts options, sourceLang, targetLang, text string) error {
+	u := googleTranslateURL(sourceLang, targetLang, text)
+	if opts.maxURLLength <= 0 || len(u) <= opts.maxURLLength {
+		return openbrowser.Start(u)
+	}
+	msg := fmt.Sprintf("URL is too long (%d > -max-url-length %d)", len(u), opts.maxURLLength)
+	switch {
+	case opts.copy:
+		// Clipboard is for the translation.
+		warnf(opts, "%s. Opening Google Translate without text as -copy copies the translation to clipboard", msg)
+	case clipboard.Unsupported:
+		warnf(opts, "%s. Opening Google Translate without text as clipboard is not supported on this platform", msg)
+	default:
+		if err := clipboard.WriteAll(text); err != nil {
+			warnf(opts, "%s. Opening Google Translate without text as copying it failed: %v", msg, err)
+		} else {
+			warnf(opts, "%s. Text is copied to clipboard. Paste it into Google Translate", msg)
+		}
+	}
+	return openbrowser.Start(googleTranslateURL(sourceLang, targetLang, ""))
+}
+
+// parseURLs returns URLs in inputs separated by whitespaces. URLs must be
+// http or https.
+func parseURLs(inputs []string) ([]string, error) {
+	var urls []string
+	for _, input := range inputs {
+		for _, s := range strings.Fields(input) {
+			u, err := url.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("invalid URL %q: must be an http or https URL", s)
+			}
+			urls = append(urls, s)
+		}
+	}
+	return urls, nil
+}
+
+// fetchInputs fetches visible text of urls. The texts are joined as one
+// input unless separate is true.
+func fetchInputs(ctx context.Context, urls []string, separate bool) ([]string, error) {
+	texts := make([]string, len(urls))
+	for i, u := range urls {
+		text, err := gtrans.FetchText(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		texts[i] = text
+	}
+	if separate {
+		return texts, nil
+	}
+	return []string{strings.Join(texts, "\n\n")}, nil
+}
+
+// isBlank reports whether all inputs are empty or whitespace-only.
+func isBlank(inputs []string) bool {
+	for _, input := range inputs {
+		if strings.TrimSpace(input) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// https://translate.google.com/?sl={source}&tl={lang}&text={input}&op=translate
+//
+// Source language is automatically detected if sourceLang is empty.
+func googleTranslateURL(sourceLang, targetLang, text string) string {
+	if sourceLang == "" {
+		sourceLang = "auto"
+	}
+	q := url.Values{}
+	q.Set("sl", sourceLang)
+	q.Set("tl", targetLang)
+	if text != "" {
+		q.Set("text", text)
+	}
+	q.Set("op", "translate")
+	return "https://translate.google.com/?" + q.Encode()
+}
+
+// result represents a translation result written by -json.
+type result struct {
+	Input  string `json:"input"`
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target"`
+	// RoundTrip is the text translated back into the source language by
+	// -roundtrip.
+	RoundTrip string `json:"roundtrip,omitempty"`
+	// Romanized is the romanized text by -romanize.
+	Romanized string `json:"romanized,omitempty"`
+	// Alternatives are alternative translations by -alternatives.
+	Alternatives []string `json:"alternatives,omitempty"`
+}
+
+// runDetection writes detected language and its confidence of each input.
+func runDetection(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, inputs []string) error {
+	opts.stats.addInputs(len(inputs))
+	if opts.detectAll {
+		return runDetectAll(ctx, w, client, opts, inputs)
+	}
+	detections, err := gtrans.DetectAll(ctx, inputs, gtrans.WithClient(client), gtrans.WithDetectionSample(opts.detectSample))
+	if err != nil {
+		return err
+	}
+	langs := make([]language.Tag, len(detections))
+	confidences := make([]float64, len(detections))
+	for i, d := range detections {
+		langs[i], confidences[i] = d.Language, d.Confidence
+	}
+	if err := checkConfidence(opts, langs, confidences); err != nil {
+		return err
+	}
+	for i, detection := range detections {
+		lang := opts.langNames.name(detection.Language)
+		if opts.brief {
+			fmt.Fprintf(w, "%s (%.2f): %s\n", lang, detection.Confidence, oneLine(inputs[i]))
+			continue
+		}
+		if detection.Language == language.Und {
+			fmt.Fprintln(w, lang)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%v\n", lang, detection.Confidence)
+	}
+	return nil
+}
+
+// runDetectAll writes all candidate languages of each input and their
+// confidence in descending order of confidence for -detect-all. Candidates
+// of inputs are separated by a blank line.
+func runDetectAll(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, inputs []string) error {
+	candidates, err := gtrans.DetectCandidates(ctx, inputs, gtrans.WithClient(client), gtrans.WithDetectionSample(opts.detectSample))
+	if err != nil {
+		return err
+	}
+	langs := make([]language.Tag, len(candidates))
+	confidences := make([]float64, len(candidates))
+	for i, ds := range candidates {
+		if len(ds) > 0 {
+			langs[i], confidences[i] = ds[0].Language, ds[0].Confidence
+		}
+	}
+	if err := checkConfidence(opts, langs, confidences); err != nil {
+		return err
+	}
+	for i, ds := range candidates {
+		if opts.brief {
+			var cs []string
+			for _, d := range ds {
+				cs = append(cs, fmt.Sprintf("%s (%.2f)", opts.langNames.name(d.Language), d.Confidence))
+			}
+			if len(cs) == 0 {
+				cs = append(cs, opts.langNames.name(language.Und))
+			}
+			fmt.Fprintf(w, "%s: %s\n", strings.Join(cs, ", "), oneLine(inputs[i]))
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(ds) == 0 {
+			fmt.Fprintln(w, opts.langNames.name(language.Und))
+		}
+		for _, d := range ds {
+			fmt.Fprintf(w, "%s\t%v\n", opts.langNames.name(d.Language), d.Confidence)
+		}
+	}
+	return nil
+}
+
+// listLanguages writes supported languages. Language names are written in
+// targetLang.
+func listLanguages(ctx context.Context, w io.Writer, client gtrans.Translator, targetLang string) error {
+	langs, err := gtrans.SupportedLanguages(ctx, targetLang, gtrans.WithClient(client))
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, lang := range langs {
+		fmt.Fprintf(tw, "%s\t%s\n", lang.Tag, lang.Name)
+	}
+	return tw.Flush()
+}
+
+// translateOptions returns options of gtrans to translate into n target
+// languages with client.
+func translateOptions(client gtrans.Translator, opts options, n int) []gtrans.Option {
+	gopts := []gtrans.Option{
+		gtrans.WithClient(client),
+		gtrans.WithSource(opts.sourceLang),
+		gtrans.WithFormat(translate.Format(opts.format)),
+		gtrans.WithModel(opts.model),
+		gtrans.WithDetectionSample(opts.detectSample),
+	}
+	if opts.chunk {
+		gopts = append(gopts, gtrans.WithChunkSize(opts.chunkSize))
+	}
+	var patterns []*regexp.Regexp
+	if opts.split == "markdown" {
+		patterns = append(patterns, gtrans.MarkdownPreservePatterns...)
+	}
+	if opts.preserve || len(opts.preserveRegexps) > 0 {
+		patterns = append(patterns, gtrans.DefaultPreservePatterns...)
+		patterns = append(patterns, opts.preserveRegexps...)
+	}
+	if len(patterns) > 0 {
+		gopts = append(gopts, gtrans.WithPreserve(patterns...))
+	}
+	if opts.keep {
+		gopts = append(gopts, gtrans.WithKeepSpans(opts.keepOpen, opts.keepClose))
+	}
+	if (opts.verbose || opts.failConf > 0) && opts.sourceLang == "" {
+		gopts = append(gopts, gtrans.WithDetection())
+	}
+	if opts.alternatives > 0 {
+		gopts = append(gopts, gtrans.WithAlternatives(opts.alternatives))
+	}
+	if opts.skipSame {
+		gopts = append(gopts, gtrans.WithSkipSame())
+	}
+	if n == 1 {
+		gopts = append(gopts, gtrans.WithSecondLang(opts.secondLang), gtrans.WithMinConfidence(opts.minConfidence))
+	}
+	return gopts
+}
+
+// runTranslation translates inputs into each target language. Results are
+// labeled with the target language if there are multiple target languages.
+func runTranslation(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, targetLangs []string, inputs []string) error {
+	translations, backs, err := translateInputs(ctx, client, opts, targetLangs, inputs)
+	if err != nil {
+		if !errors.As(err, new(*partialError)) {
+			return err
+		}
+		// Write the rest of translations without round trip.
+		opts.roundTrip = false
+	}
+	if opts.htmlToText {
+		htmlToText(translations, backs)
+	}
+	if werr := writeTranslations(w, opts, len(targetLangs), translations, backs); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// translateInputs translates inputs into each of targetLangs. Translations
+// are in order of target languages and then inputs. It also returns round
+// trip translations corresponding to the translations with -roundtrip. If
+// some inputs fail to translate, it returns the rest of translations with a
+// *partialError.
+func translateInputs(ctx context.Context, client gtrans.Translator, opts options, targetLangs []string, inputs []string) ([]gtrans.Translation, []string, error) {
+	opts.stats.addInputs(len(inputs) * len(targetLangs))
+	gopts := translateOptions(client, opts, len(targetLangs))
+	if opts.tsv && opts.split != "" {
+		// Translate segments as inputs to write a pair for each segment.
+		inputs = splitSegments(inputs, splitFuncs[opts.split])
+		opts.split = ""
+	}
+	var translations []gtrans.Translation
+	var perr *partialError
+	for _, targetLang := range targetLangs {
+		ts, err := translateAll(ctx, inputs, targetLang, opts, gopts)
+		var p *partialError
+		if errors.As(err, &p) {
+			if perr == nil {
+				perr = &partialError{}
+			}
+			perr.failed += p.failed
+			perr.total += p.total
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if err := checkTranslationConfidence(opts, ts); err != nil {
+			return nil, nil, err
+		}
+		translations = append(translations, ts...)
+		if len(targetLangs) == 1 {
+			warnLowConfidence(opts, targetLang, ts)
+		}
+	}
+	if len(targetLangs) == 1 && opts.sourceLang == "" {
+		opts.lastSource.record(translations)
+	}
+	// Finish progress before writing translations not to mix them.
+	opts.progress.finish()
+	if perr != nil {
+		// Round trip of the placeholders would fail or be misleading.
+		return translations, nil, perr
+	}
+	if !opts.roundTrip {
+		return translations, nil, nil
+	}
+	backs, err := roundTrip(ctx, translations, gopts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return translations, backs, nil
+}
+
+// writeTranslations writes translations into n target languages in the
+// output format of opts. backs are round trip translations and can be nil.
+func writeTranslations(w io.Writer, opts options, n int, translations []gtrans.Translation, backs []string) error {
+	if opts.verbose {
+		for _, t := range translations {
+			writeVerbose(os.Stderr, opts, t)
+		}
+	}
+	if opts.copy {
+		copyToClipboard(opts, translations)
+	}
+	if opts.notify {
+		notify(opts, translations)
+	}
+	if opts.jsonOutput {
+		return writeJSON(w, translations, backs, opts.romanize)
+	}
+	if opts.tsv {
+		return writeTSV(w, translations, n)
+	}
+	// Results are joined with -separator.
+	results := make([]string, len(translations))
+	for i, translation := range translations {
+		var out strings.Builder
+		switch {
+		case opts.roundTrip:
+			writeRoundTrip(&out, opts, translation, backs[i])
+		case opts.showOriginal:
+			writeWithOriginal(&out, opts, translation)
+		case opts.brief:
+			fmt.Fprintf(&out, "%s->%s: %s\n", opts.langNames.name(translation.Source), opts.langNames.name(translation.Target), displayText(opts, translation.Target, oneLine(translation.Text)))
+		default:
+			if n > 1 {
+				fmt.Fprintf(&out, "%s: ", translation.Target)
+			}
+			fmt.Fprintln(&out, displayText(opts, translation.Target, translation.Text))
+		}
+		for _, alt := range translation.Alternatives {
+			fmt.Fprintf(&out, "  %s\n", displayText(opts, translation.Target, alt))
+		}
+		if opts.romanize {
+			fmt.Fprintf(&out, "romanized: %s\n", gtrans.Romanize(translation.Text))
+		}
+		results[i] = strings.TrimSuffix(out.String(), "\n")
+	}
+	text := ""
+	if len(results) > 0 {
+		text = strings.Join(results, opts.separator) + "\n"
+	}
+	if opts.noNewline {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+// unescape interprets escape sequences of Go string literals such as \n,
+// \t and \u3000 in s.
+func unescape(s string) (string, error) {
+	return strconv.Unquote(`"` + strings.ReplaceAll(s, `"`, `\"`) + `"`)
+}
+
+// dedupe returns unique texts in order of appearance and indices of them
+// corresponding to texts.
+func dedupe(texts []string) ([]string, []int) {
+	var uniq []string
+	indices := make([]int, len(texts))
+	seen := make(map[string]int)
+	for i, text := range texts {
+		j, ok := seen[text]
+		if !ok {
+			j = len(uniq)
+			seen[text] = j
+			uniq = append(uniq, text)
+		}
+		indices[i] = j
+	}
+	return uniq, indices
+}
+
+// writeVerbose writes diagnostic information of translation t.
+func writeVerbose(w io.Writer, opts options, t gtrans.Translation) {
+	source, target := opts.langNames.name(t.Source), opts.langNames.name(t.Target)
+	if opts.swap {
+		fmt.Fprintf(w, "source: %s (swapped by -swap), target: %s\n", source, target)
+		return
+	}
+	if opts.sourceLang != "" {
+		fmt.Fprintf(w, "source: %s (specified by -from), target: %s\n", source, target)
+		return
+	}
+	fmt.Fprintf(w, "source: %s (detected, confidence: %v), target: %s\n", source, t.Confidence, target)
+}
+
+// checkTranslationConfidence is checkConfidence for source languages of
+// translations.
+func checkTranslationConfidence(opts options, translations []gtrans.Translation) error {
+	langs := make([]language.Tag, len(translations))
+	confidences := make([]float64, len(translations))
+	for i, t := range translations {
+		langs[i], confidences[i] = t.Source, t.Confidence
+	}
+	return checkConfidence(opts, langs, confidences)
+}
+
+// checkConfidence returns an error if confidence of a detected source
+// language is lower than -fail-on-low-confidence, in which case the input
+// may be translated in the wrong direction.
+func checkConfidence(opts options, langs []language.Tag, confidences []float64) error {
+	if opts.failConf <= 0 || opts.sourceLang != "" {
+		return nil
+	}
+	for i, conf := range confidences {
+		if conf < opts.failConf {
+			return fmt.Errorf("confidence of detected language %s is %.2f, lower than -fail-on-low-confidence %v. Give the source language by -from", opts.langNames.name(langs[i]), conf, opts.failConf)
+		}
+	}
+	return nil
+}
+
+// warnLowConfidence warns if target language is not switched to the second
+// language due to -min-confidence.
+func warnLowConfidence(opts options, targetLang string, translations []gtrans.Translation) {
+	if opts.secondLang == "" || opts.sourceLang != "" {
+		return
+	}
+	for _, t := range translations {
+		if t.Confidence < opts.minConfidence && gtrans.SwitchTargetLang(t.Source.String(), targetLang, opts.secondLang) != targetLang {
+			warnf(opts, "confidence of detected language %s is low (%.2f < %v). Target language is not switched to %s", t.Source, t.Confidence, opts.minConfidence, opts.secondLang)
+			return
+		}
+	}
+}
+
+// warnf writes a warning to STDERR unless -quiet or -log-level error is
+// given.
+func warnf(opts options, format string, a ...interface{}) {
+	opts.log.Warnf(format, a...)
+}
+
+// copyToClipboard copies translated texts to the system clipboard. It only
+// warns on failure so that translated result is still written.
+func copyToClipboard(opts options, translations []gtrans.Translation) {
+	if clipboard.Unsupported {
+		warnf(opts, "clipboard is not supported on this platform")
+		return
+	}
+	texts := make([]string, len(translations))
+	for i, t := range translations {
+		texts[i] = t.Text
+	}
+	if err := clipboard.WriteAll(strings.Join(texts, "\n")); err != nil {
+		warnf(opts, "failed to copy to clipboard: %v", err)
+	}
+}
+
+// oneLine returns text in one line by collapsing whitespaces including
+// newlines into a space.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// writeWithOriginal writes the original text and the translated text on two
+// lines labeled with their languages. The label of the translated text
+// shows which target language is chosen.
+func writeWithOriginal(w io.Writer, opts options, t gtrans.Translation) {
+	src := "original"
+	if t.Source != language.Und {
+		src = opts.langNames.name(t.Source)
+	}
+	fmt.Fprintf(w, "%s: %s\n", src, colorize(opts, colorOriginal, strings.TrimRight(t.Input, "\r\n")))
+	fmt.Fprintf(w, "%s: %s\n", opts.langNames.name(t.Target), colorize(opts, colorTranslation, displayText(opts, t.Target, t.Text)))
+}
+
+// splitFuncs maps -split values to functions which split input into segments.
+var splitFuncs = map[string]func(string) *gtrans.Segments{
+	"":          nil,
+	"line":      gtrans.SplitLines,
+	"paragraph": gtrans.SplitParagraphs,
+	"srt":       gtrans.SplitSRT,
+	"markdown":  gtrans.SplitMarkdown,
+}
+
+// translateAll translates inputs into targetLang. If -split is given, each
+// input is split into segments, which are translated in one request and
+// joined with the original separators to preserve the structure of input.
+func translateAll(ctx context.Context, inputs []string, targetLang string, opts options, gopts []gtrans.Option) ([]gtrans.Translation, error) {
+	translate := func(texts []string) ([]gtrans.Translation, error) {
+		// Translate identical texts such as repeated lines only once.
+		uniq, indices := dedupe(texts)
+		var ts []gtrans.Translation
+		var err error
+		opts.progress.add(len(uniq))
+		if opts.concurrency > 1 && len(uniq) > 1 {
+			ts, err = translateParallel(ctx, uniq, targetLang, gopts, opts.concurrency, opts.progress)
+		} else {
+			ts, err = gtrans.TranslateAll(ctx, uniq, targetLang, gopts...)
+			if err == nil {
+				opts.progress.inc(len(uniq))
+			}
+		}
+		var perr *partialError
+		if err != nil && len(uniq) > 1 && isInputError(err) {
+			// A malformed input can fail the whole batch. Translate inputs
+			// one by one so that the rest are still translated.
+			ts, err = translateEach(ctx, uniq, targetLang, gopts, opts.log, err)
+			if errors.As(err, &perr) {
+				err = nil
+			}
+			if err == nil && opts.concurrency <= 1 {
+				opts.progress.inc(len(uniq))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		results := make([]gtrans.Translation, len(texts))
+		for i, j := range indices {
+			results[i] = ts[j]
+		}
+		if perr != nil {
+			return results, perr
+		}
+		return results, nil
+	}
+	splitFunc := splitFuncs[opts.split]
+	if splitFunc == nil && opts.preserveSpace {
+		splitFunc = gtrans.SplitSpace
+	}
+	if splitFunc == nil {
+		return translate(inputs)
+	}
+	segs := make([]*gtrans.Segments, len(inputs))
+	var texts []string
+	for i, input := range inputs {
+		segs[i] = splitFunc(input)
+		// Indentation and surrounding whitespace of segments are kept as
+		// they are since the API may trim or alter them.
+		segs[i].TrimSpace()
+		texts = append(texts, segs[i].Texts...)
+	}
+	var ts []gtrans.Translation
+	var err error
+	if len(texts) > 0 {
+		ts, err = translate(texts)
+		if err != nil && !errors.As(err, new(*partialError)) {
+			return nil, err
+		}
+	}
+	results := make([]gtrans.Translation, len(inputs))
+	for i, seg := range segs {
+		results[i] = gtrans.Translation{Input: inputs[i], Target: language.Make(targetLang)}
+		out := make([]string, len(seg.Texts))
+		for j := range seg.Texts {
+			t := ts[0]
+			ts = ts[1:]
+			out[j] = t.Text
+			// Target may be switched by the second language.
+			results[i].Target = t.Target
+			if results[i].Source == language.Und {
+				results[i].Source = t.Source
+				results[i].Confidence = t.Confidence
+			}
+		}
+		// Trailing newlines are written by output instead, as is the case
+		// with whole input translation.
+		results[i].Text = strings.TrimRight(seg.Join(out), "\r\n")
+	}
+	// err is nil or a *partialError.
+	return results, err
+}
+
+// splitSegments returns segments of inputs split by splitFunc.
+func splitSegments(inputs []string, splitFunc func(string) *gtrans.Segments) []string {
+	var segments []string
+	for _, input := range inputs {
+		seg := splitFunc(input)
+		seg.TrimSpace()
+		segments = append(segments, seg.Texts...)
+	}
+	return segments
+}
+
+// tsvEscaper escapes characters which can't be in TSV fields.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\r", `\r`, "\n", `\n`)
+
+// writeTSV writes a line of each input and its translations into n target
+// languages separated by tabs. translations are in order of target languages
+// and then inputs.
+func writeTSV(w io.Writer, translations []gtrans.Translation, n int) error {
+	var out strings.Builder
+	inputs := len(translations) / n
+	for i, t := range translations[:inputs] {
+		out.WriteString(tsvEscaper.Replace(strings.TrimRight(t.Input, "\r\n")))
+		for j := i; j < len(translations); j += inputs {
+			out.WriteByte('\t')
+			out.WriteString(tsvEscaper.Replace(strings.TrimRight(translations[j].Text, "\r\n")))
+		}
+		out.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// writeJSON writes translations as a JSON object, or as an array of objects
+// if there are multiple translations. backs are round trip translations and
+// can be nil.
+func writeJSON(w io.Writer, translations []gtrans.Translation, backs []string, romanize bool) error {
+	results := make([]result, len(translations))
+	for i, t := range translations {
+		results[i] = result{Input: t.Input, Text: t.Text, Target: t.Target.String(), Alternatives: t.Alternatives}
+		if t.Source != language.Und {
+			results[i].Source = t.Source.String()
+		}
+		if backs != nil {
+			results[i].RoundTrip = backs[i]
+		}
+		if romanize {
+			results[i].Romanized = gtrans.Romanize(t.Text)
+		}
+	}
+	enc := json.NewEncoder(w)
+	if len(results) == 1 {
+		return enc.Encode(results[0])
+	}
+	return enc.Encode(results)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGoogleTranslateURL(t *testing.T) {
+	tests := []struct {
+		sourceLang, targetLang, text string
+		want                         string
+		wantQuery                    url.Values
+	}{
+		{
+			sourceLang: "", targetLang: "ja", text: "Hello world",
+			want:      "https://translate.google.com/?op=translate&sl=auto&text=Hello+world&tl=ja",
+			wantQuery: url.Values{"sl": {"auto"}, "tl": {"ja"}, "text": {"Hello world"}, "op": {"translate"}},
+		},
+		{
+			sourceLang: "en", targetLang: "ja", text: "Tom & Jerry",
+			want:      "https://translate.google.com/?op=translate&sl=en&text=Tom+%26+Jerry&tl=ja",
+			wantQuery: url.Values{"sl": {"en"}, "tl": {"ja"}, "text": {"Tom & Jerry"}, "op": {"translate"}},
+		},
+		{
+			sourceLang: "ja", targetLang: "en", text: "素晴らしい",
+			want:      "https://translate.google.com/?op=translate&sl=ja&text=%E7%B4%A0%E6%99%B4%E3%82%89%E3%81%97%E3%81%84&tl=en",
+			wantQuery: url.Values{"sl": {"ja"}, "tl": {"en"}, "text": {"素晴らしい"}, "op": {"translate"}},
+		},
+		{
+			sourceLang: "", targetLang: "en", text: "",
+			want:      "https://translate.google.com/?op=translate&sl=auto&tl=en",
+			wantQuery: url.Values{"sl": {"auto"}, "tl": {"en"}, "op": {"translate"}},
+		},
+	}
+	for _, tt := range tests {
+		got := googleTranslateURL(tt.sourceLang, tt.targetLang, tt.text)
+		if got != tt.want {
+			t.Errorf("googleTranslateURL(%q, %q, %q) = %q, want %q", tt.sourceLang, tt.targetLang, tt.text, got, tt.want)
+			continue
+		}
+		u, err := url.Parse(got)
+		if err != nil {
+			t.Errorf("googleTranslateURL(%q, %q, %q) returned invalid URL: %v", tt.sourceLang, tt.targetLang, tt.text, err)
+			continue
+		}
+		q := u.Query()
+		for _, key := range []string{"sl", "tl", "text", "op"} {
+			if q.Get(key) != tt.wantQuery.Get(key) {
+				t.Errorf("googleTranslateURL(%q, %q, %q): %s = %q, want %q", tt.sourceLang, tt.targetLang, tt.text, key, q.Get(key), tt.wantQuery.Get(key))
+			}
+		}
+	}
+}
+
+func TestLeveledLoggerMasksSecrets(t *testing.T) {
+	const key = "AIzaSyDUMMYKEY"
+	err := &url.Error{
+		Op:  "Post",
+		URL: "https://translation.googleapis.com/language/translate/v2?alt=json&key=" + key,
+		Err: errors.New("dial tcp: connection refused"),
+	}
+	for _, level := range []logLevel{levelError, levelDebug} {
+		var buf bytes.Buffer
+		l := &leveledLogger{w: &buf, level: level, secrets: []string{key}}
+		l.Errorf("%v", err)
+		got := buf.String()
+		if strings.Contains(got, key) {
+			t.Errorf("Errorf(%q) at level %d leaked the key: %q", err, level, got)
+		}
+		if want := "error: Post \"https://translation.googleapis.com/language/translate/v2?alt=json&key=<masked>\": dial tcp: connection refused\n"; got != want {
+			t.Errorf("Errorf(%q) at level %d wrote %q, want %q", err, level, got, want)
+		}
+	}
+}
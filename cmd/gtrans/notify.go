@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gen2brain/beeep"
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// notify sends a desktop notification of translated texts. It only warns on
+// failure such as unsupported platforms so that translated result is still
+// written.
+func notify(opts options, translations []gtrans.Translation) {
+	if len(translations) == 0 {
+		return
+	}
+	texts := make([]string, len(translations))
+	for i, t := range translations {
+		texts[i] = t.Text
+	}
+	title := "gtrans"
+	if t := translations[0]; t.Source != language.Und {
+		title = fmt.Sprintf("gtrans (%s -> %s)", t.Source, t.Target)
+	}
+	beeep.AppName = "gtrans"
+	if err := beeep.Notify(title, strings.Join(texts, "\n"), ""); err != nil {
+		warnf(opts, "failed to send notification: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// translateParallel translates each input in its own request with n workers.
+// Results are in the same order as inputs. It returns the first error and
+// cancels the rest of the requests. Completed inputs are reported to p.
+func translateParallel(ctx context.Context, inputs []string, targetLang string, gopts []gtrans.Option, n int, p *progress) ([]gtrans.Translation, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]gtrans.Translation, len(inputs))
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ts, err := gtrans.TranslateAll(ctx, inputs[i:i+1], targetLang, gopts...)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				results[i] = ts[0]
+				p.inc(1)
+			}
+		}()
+	}
+loop:
+	for i := range inputs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
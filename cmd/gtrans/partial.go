@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/haya14busa/gtrans"
+	"golang.org/x/text/language"
+	"google.golang.org/api/googleapi"
+)
+
+// partialError is returned with translations when some inputs fail to
+// translate. Translations of the failed inputs are empty placeholders so that
+// the rest keep their positions.
+type partialError struct {
+	failed int
+	total  int
+}
+
+func (e *partialError) Error() string {
+	return fmt.Sprintf("failed to translate %d of %d inputs", e.failed, e.total)
+}
+
+// isInputError reports whether err is likely caused by some of the inputs of
+// the request rather than the request itself, in which case translating the
+// inputs one by one may succeed for the rest.
+func isInputError(err error) bool {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return (gerr.Code == http.StatusBadRequest && !isKeyInvalid(gerr)) || gerr.Code == http.StatusRequestEntityTooLarge
+	}
+	var aerr *gtrans.APIError
+	if errors.As(err, &aerr) {
+		return aerr.Code == http.StatusBadRequest || aerr.Code == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// translateEach translates texts one by one after a request of all of them
+// failed with err. Failed texts are reported to log with their indices and
+// have empty translations. It returns a *partialError if some of texts fail
+// and err if all of them fail.
+func translateEach(ctx context.Context, texts []string, targetLang string, gopts []gtrans.Option, log *leveledLogger, err error) ([]gtrans.Translation, error) {
+	results := make([]gtrans.Translation, len(texts))
+	failed := 0
+	for i, text := range texts {
+		ts, terr := gtrans.TranslateAll(ctx, texts[i:i+1], targetLang, gopts...)
+		if ctx.Err() != nil {
+			return nil, terr
+		}
+		if terr != nil {
+			log.Errorf("input %d (%s): %v", i+1, shorten(oneLine(text), 40), terr)
+			results[i] = gtrans.Translation{Input: text, Target: language.Make(targetLang)}
+			failed++
+			continue
+		}
+		results[i] = ts[0]
+	}
+	if failed == len(texts) {
+		return nil, err
+	}
+	if failed > 0 {
+		return results, &partialError{failed: failed, total: len(texts)}
+	}
+	return results, nil
+}
+
+// shorten returns text truncated to n characters for messages.
+func shorten(text string, n int) string {
+	if r := []rune(text); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum interval of progress lines when STDERR is
+// not a terminal.
+const progressInterval = time.Second
+
+// progress writes the number of translated texts to STDERR for -progress.
+// The line is updated in place on a terminal. Otherwise, lines are written
+// periodically. Methods of nil *progress do nothing.
+type progress struct {
+	mu    sync.Mutex
+	w     *os.File
+	tty   bool
+	total int
+	done  int
+	last  time.Time
+}
+
+func newProgress(w *os.File) *progress {
+	p := &progress{w: w}
+	if fi, err := w.Stat(); err == nil {
+		p.tty = fi.Mode()&os.ModeCharDevice != 0
+	}
+	return p
+}
+
+// add adds n texts to be translated.
+func (p *progress) add(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += n
+	p.write(false)
+}
+
+// inc marks n texts as translated.
+func (p *progress) inc(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.write(false)
+}
+
+// finish writes the last progress and ends the line. Progress after finish
+// starts over.
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.total == 0 {
+		return
+	}
+	p.write(true)
+	if p.tty {
+		fmt.Fprintln(p.w)
+	}
+	p.total, p.done = 0, 0
+}
+
+func (p *progress) write(force bool) {
+	if !p.tty && !force && time.Since(p.last) < progressInterval {
+		return
+	}
+	p.last = time.Now()
+	percent := 0
+	if p.total > 0 {
+		percent = p.done * 100 / p.total
+	}
+	if p.tty {
+		// Clear the rest of the line as the previous line can be longer.
+		fmt.Fprintf(p.w, "\rtranslated %d/%d (%d%%)\x1b[K", p.done, p.total, percent)
+		return
+	}
+	fmt.Fprintf(p.w, "translated %d/%d (%d%%)\n", p.done, p.total, percent)
+}
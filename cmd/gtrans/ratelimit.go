@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+	"golang.org/x/time/rate"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// rateLimitClient is a Translator which throttles requests to respect
+// per-minute quotas of the API. Requests block until they are allowed. It
+// wraps the backend client inside the retry client so that retries are
+// throttled as well.
+type rateLimitClient struct {
+	gtrans.Translator
+	// requests limits the number of requests. It's nil without -rps.
+	requests *rate.Limiter
+	// chars limits the number of characters. It's nil without
+	// -chars-per-min.
+	chars *rate.Limiter
+	log   *leveledLogger
+}
+
+// newRateLimitClient returns client throttled to rps requests per second
+// and charsPerMin characters per minute. Zero means no limit.
+func newRateLimitClient(client gtrans.Translator, rps float64, charsPerMin int, log *leveledLogger) gtrans.Translator {
+	c := &rateLimitClient{Translator: client, log: log}
+	if rps > 0 {
+		c.requests = rate.NewLimiter(rate.Limit(rps), int(math.Max(1, rps)))
+	}
+	if charsPerMin > 0 {
+		c.chars = rate.NewLimiter(rate.Limit(float64(charsPerMin)/60), charsPerMin)
+	}
+	return c
+}
+
+func (c *rateLimitClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	if err := c.wait(ctx, countChars(inputs)); err != nil {
+		return nil, err
+	}
+	return c.Translator.Translate(ctx, inputs, target, opts)
+}
+
+func (c *rateLimitClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	if err := c.wait(ctx, countChars(inputs)); err != nil {
+		return nil, err
+	}
+	return c.Translator.DetectLanguage(ctx, inputs)
+}
+
+func (c *rateLimitClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	if err := c.wait(ctx, 0); err != nil {
+		return nil, err
+	}
+	return c.Translator.SupportedLanguages(ctx, target)
+}
+
+func (c *rateLimitClient) TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	if err := c.wait(ctx, countChars([]string{input})); err != nil {
+		return nil, err
+	}
+	return gtrans.TranslateAlternatives(ctx, c.Translator, input, target, opts, n)
+}
+
+func (c *rateLimitClient) Unwrap() gtrans.Translator {
+	return c.Translator
+}
+
+// wait blocks until a request of n characters is allowed.
+func (c *rateLimitClient) wait(ctx context.Context, n int) error {
+	now := time.Now()
+	var delay time.Duration
+	var reservations []*rate.Reservation
+	if c.requests != nil {
+		r := c.requests.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		delay = r.DelayFrom(now)
+	}
+	if c.chars != nil && n > 0 {
+		// A request larger than the limit is allowed when the bucket is full.
+		if n > c.chars.Burst() {
+			n = c.chars.Burst()
+		}
+		r := c.chars.ReserveN(now, n)
+		reservations = append(reservations, r)
+		if d := r.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+	if delay <= 0 {
+		return nil
+	}
+	c.log.Infof("rate limit: waiting %v", delay.Round(time.Millisecond))
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		for _, r := range reservations {
+			r.Cancel()
+		}
+		return ctx.Err()
+	}
+}
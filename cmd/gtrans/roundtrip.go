@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// roundTrip translates translated texts back into their source languages to
+// see how much meaning drifted. Translations with the same language pair are
+// translated back in one request.
+func roundTrip(ctx context.Context, translations []gtrans.Translation, gopts []gtrans.Option) ([]string, error) {
+	type pair struct{ source, target language.Tag }
+	var pairs []pair
+	indices := make(map[pair][]int)
+	for i, t := range translations {
+		if t.Source == language.Und {
+			return nil, fmt.Errorf("cannot translate back %q: source language is unknown", t.Text)
+		}
+		p := pair{source: t.Source, target: t.Target}
+		if _, ok := indices[p]; !ok {
+			pairs = append(pairs, p)
+		}
+		indices[p] = append(indices[p], i)
+	}
+
+	backs := make([]string, len(translations))
+	for _, p := range pairs {
+		texts := make([]string, len(indices[p]))
+		for j, i := range indices[p] {
+			texts[j] = translations[i].Text
+		}
+		// Reuse the detected source language as the target language of the
+		// reverse direction.
+		opts := append(gopts[:len(gopts):len(gopts)], gtrans.WithSource(p.target.String()), gtrans.WithSecondLang(""))
+		ts, err := gtrans.TranslateAll(ctx, texts, p.source.String(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range indices[p] {
+			backs[i] = ts[j].Text
+		}
+	}
+	return backs, nil
+}
+
+// writeRoundTrip writes forward and reverse translations with labels.
+func writeRoundTrip(w io.Writer, opts options, t gtrans.Translation, back string) {
+	fmt.Fprintf(w, "forward (%s -> %s): %s\n", t.Source, t.Target, colorize(opts, colorTranslation, t.Text))
+	fmt.Fprintf(w, "reverse (%s -> %s): %s\n", t.Target, t.Source, colorize(opts, colorOriginal, back))
+}
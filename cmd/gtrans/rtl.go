@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// rtlScripts are scripts written from right to left.
+var rtlScripts = map[string]bool{
+	"Adlm": true, "Arab": true, "Hebr": true, "Mand": true, "Nkoo": true,
+	"Rohg": true, "Samr": true, "Syrc": true, "Thaa": true,
+}
+
+// Unicode directional formatting characters to embed right-to-left text.
+const (
+	rightToLeftEmbedding = "\u202b"
+	popDirectionalFormat = "\u202c"
+)
+
+// isRTL reports whether tag is written from right to left. The script is
+// guessed from the language if it's not given (e.g. Arab for ar).
+func isRTL(tag language.Tag) bool {
+	script, conf := tag.Script()
+	return conf != language.No && rtlScripts[script.String()]
+}
+
+// useRTLMarks reports whether right-to-left translations written to w are
+// wrapped with directional marks for -rtl mode. In auto mode, they are
+// wrapped only if w is a terminal not to change text written to files and
+// pipes.
+func useRTLMarks(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return isTerminal(w), nil
+	}
+	return false, fmt.Errorf("invalid -rtl value %q: must be auto, always or never", mode)
+}
+
+// displayText returns text in target language for text output. Each line of
+// right-to-left text is embedded in right-to-left direction with -rtl so
+// that terminals display punctuation such as a trailing "!" on the correct
+// side.
+func displayText(opts options, target language.Tag, text string) string {
+	if !opts.rtlMarks || !isRTL(target) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = rightToLeftEmbedding + line + popDirectionalFormat
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// Sources of configuration values.
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceFile    = "file"
+	sourceDefault = "default"
+)
+
+// setting is a configuration value resolved from a flag, an environment
+// variable or the config file.
+type setting struct {
+	value string
+	// source is sourceFlag, sourceEnv, sourceFile or sourceDefault.
+	source string
+	// origin is the name of the flag or the environment variable, or the
+	// path of the config file the value comes from.
+	origin string
+}
+
+// settings is the effective configuration after merging flags, environment
+// variables and the config file. Flags take precedence over environment
+// variables, which take precedence over the config file.
+type settings struct {
+	configFile    string
+	backend       setting
+	lang          setting
+	secondLang    setting
+	apiKey        setting
+	keyFile       setting
+	endpoint      setting
+	project       setting
+	userAgent     setting
+	timeout       setting
+	output        setting
+	deeplKey      setting
+	libreURL      setting
+	libreKey      setting
+	azureKey      setting
+	azureRegion   setting
+	azureEndpoint setting
+}
+
+// resolver resolves settings in order of precedence.
+type resolver struct {
+	// set is the set of flags given on the command line.
+	set  map[string]bool
+	file string
+}
+
+// resolve returns the value of the flag if it's set and not empty, otherwise
+// the value of the environment variable env, otherwise fileValue of the
+// config file, otherwise def. name and env can be empty if the setting has
+// no flag or environment variable.
+func (r *resolver) resolve(name, flagValue, env, fileValue, def string) setting {
+	if name != "" && r.set[name] && flagValue != "" {
+		return setting{value: flagValue, source: sourceFlag, origin: "-" + name}
+	}
+	if env != "" {
+		if v := os.Getenv(env); v != "" {
+			return setting{value: v, source: sourceEnv, origin: "$" + env}
+		}
+	}
+	if fileValue != "" {
+		return setting{value: fileValue, source: sourceFile, origin: r.file}
+	}
+	return setting{value: def, source: sourceDefault}
+}
+
+// resolveSettings resolves the configuration from flags, environment
+// variables and cfg loaded from the config file at path, and sets the
+// effective values to opts.
+func resolveSettings(opts *options, cfg *fileConfig, path string) *settings {
+	r := &resolver{set: make(map[string]bool), file: path}
+	flag.Visit(func(f *flag.Flag) { r.set[f.Name] = true })
+	// -primary is copied to the target language.
+	langFlag := "to"
+	if opts.primaryLang != "" {
+		langFlag = "primary"
+		r.set[langFlag] = true
+	}
+	s := &settings{configFile: path}
+	s.backend = r.resolve("backend", opts.backend, "", "", opts.backend)
+	s.lang = r.resolve(langFlag, opts.targetLang, "GOOGLE_TRANSLATE_LANG", cfg.Lang, "")
+	s.secondLang = r.resolve("secondary", opts.secondaryLang, "GOOGLE_TRANSLATE_SECOND_LANG", cfg.SecondLang, "")
+	s.apiKey = r.resolve("api-key", opts.apiKey, "GOOGLE_TRANSLATE_API_KEY", cfg.APIKey, "")
+	s.keyFile = r.resolve("key-file", opts.keyFile, "GOOGLE_TRANSLATE_API_KEY_FILE", "", "")
+	s.endpoint = r.resolve("endpoint", opts.endpoint, "GOOGLE_TRANSLATE_ENDPOINT", "", "")
+	s.project = r.resolve("project", opts.project, "GOOGLE_CLOUD_PROJECT", "", "")
+	s.userAgent = r.resolve("user-agent", opts.userAgent, "GTRANS_USER_AGENT", "", "gtrans/"+buildVersion())
+	var fileTimeout string
+	if cfg.Timeout.Duration > 0 {
+		fileTimeout = cfg.Timeout.Duration.String()
+	}
+	s.timeout = r.resolve("timeout", opts.timeout.String(), "", fileTimeout, opts.timeout.String())
+	s.output = r.resolve("json", strconv.FormatBool(opts.jsonOutput), "", cfg.Output, "text")
+	if s.output.source == sourceFlag {
+		s.output.value = "text"
+		if opts.jsonOutput {
+			s.output.value = "json"
+		}
+	}
+	s.deeplKey = r.resolve("", "", "DEEPL_API_KEY", "", "")
+	s.libreURL = r.resolve("", "", "LIBRETRANSLATE_URL", "", gtrans.DefaultLibreTranslateURL)
+	s.libreKey = r.resolve("", "", "LIBRETRANSLATE_API_KEY", "", "")
+	s.azureKey = r.resolve("", "", "AZURE_TRANSLATOR_KEY", "", "")
+	s.azureRegion = r.resolve("", "", "AZURE_TRANSLATOR_REGION", "", "")
+	s.azureEndpoint = r.resolve("", "", "AZURE_TRANSLATOR_ENDPOINT", "", gtrans.DefaultAzureEndpoint)
+
+	opts.targetLang = s.lang.value
+	opts.secondLang = gtrans.NormalizeLangCode(s.secondLang.value)
+	opts.endpoint = s.endpoint.value
+	opts.project = s.project.value
+	opts.userAgent = s.userAgent.value
+	if s.timeout.source == sourceFile {
+		opts.timeout = cfg.Timeout.Duration
+	}
+	opts.jsonOutput = s.output.value == "json"
+	return s
+}
+
+// writeSettings writes the effective configuration and the source of each
+// value for -config-dump. Secret values are masked.
+func writeSettings(w io.Writer, s *settings) error {
+	if _, err := os.Stat(s.configFile); err != nil {
+		fmt.Fprintf(w, "config file: %s (not found)\n", s.configFile)
+	} else {
+		fmt.Fprintf(w, "config file: %s\n", s.configFile)
+	}
+	lang := s.lang
+	if lang.value == "" {
+		// The same as the target language used when translating.
+		if code, err := gtrans.DefaultTargetLang(); err == nil {
+			lang = setting{value: code, source: sourceDefault, origin: "locale"}
+		}
+	}
+	apiKey := s.apiKey
+	if s.keyFile.value != "" && apiKey.source != sourceFlag {
+		// The key file takes precedence over the environment variable and
+		// the config file.
+		apiKey = setting{value: "<read from key_file>", source: s.keyFile.source, origin: s.keyFile.origin}
+	} else if apiKey.value != "" {
+		apiKey.value = "<masked>"
+	}
+	type entry struct {
+		name string
+		setting
+	}
+	entries := []entry{
+		{"backend", s.backend},
+		{"lang", lang},
+		{"second_lang", s.secondLang},
+		{"timeout", s.timeout},
+		{"output", s.output},
+		{"user_agent", s.userAgent},
+	}
+	switch s.backend.value {
+	case "google":
+		entries = append(entries, entry{"api_key", apiKey}, entry{"key_file", s.keyFile}, entry{"endpoint", s.endpoint}, entry{"project", s.project})
+	case "deepl":
+		entries = append(entries, entry{"deepl_api_key", masked(s.deeplKey)})
+	case "libretranslate":
+		entries = append(entries, entry{"libretranslate_url", s.libreURL}, entry{"libretranslate_api_key", masked(s.libreKey)})
+	case "azure":
+		entries = append(entries, entry{"azure_key", masked(s.azureKey)}, entry{"azure_region", s.azureRegion}, entry{"azure_endpoint", s.azureEndpoint})
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, e := range entries {
+		value := e.value
+		if value == "" {
+			value = "(not set)"
+		}
+		source := e.source
+		if e.origin != "" {
+			source += " " + e.origin
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.name, value, source)
+	}
+	return tw.Flush()
+}
+
+// secrets returns the resolved API keys of all backends to mask them in
+// errors and logs. Longer keys come first so that a key containing another
+// one is masked as a whole.
+func secrets(opts options) []string {
+	s := opts.settings
+	keys := []string{opts.apiKey, s.apiKey.value, s.deeplKey.value, s.libreKey.value, s.azureKey.value}
+	if key, err := apiKey(opts); err == nil {
+		// The key read from the key file.
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	return keys
+}
+
+// masked returns s whose value is masked if it's set.
+func masked(s setting) setting {
+	if s.value != "" {
+		s.value = "<masked>"
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// stats counts usage of the API in a run for -stats. Methods of nil *stats
+// do nothing.
+type stats struct {
+	mu     sync.Mutex
+	start  time.Time
+	inputs int
+	chars  int
+	calls  int
+}
+
+// addInputs adds n inputs translated or detected.
+func (s *stats) addInputs(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputs += n
+}
+
+// addCall adds an API call. chars are counted only if the call succeeds
+// since failed calls are not billed.
+func (s *stats) addCall(chars int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if err == nil {
+		s.chars += chars
+	}
+}
+
+func (s *stats) write(w io.Writer) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(w, "inputs: %d\n", s.inputs)
+	fmt.Fprintf(w, "characters: %d\n", s.chars)
+	fmt.Fprintf(w, "API calls: %d\n", s.calls)
+	fmt.Fprintf(w, "elapsed: %v\n", time.Since(s.start).Round(time.Millisecond))
+}
+
+// statsClient is a Translator which counts API calls and billable
+// characters. It wraps the backend client inside the retry client so that
+// each retry is counted as a call, and inside the cache client so that
+// cached translations are not counted.
+type statsClient struct {
+	gtrans.Translator
+	stats *stats
+}
+
+func (c *statsClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	translations, err := c.Translator.Translate(ctx, inputs, target, opts)
+	c.stats.addCall(countChars(inputs), err)
+	return translations, err
+}
+
+func (c *statsClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	detections, err := c.Translator.DetectLanguage(ctx, inputs)
+	c.stats.addCall(countChars(inputs), err)
+	return detections, err
+}
+
+func (c *statsClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	langs, err := c.Translator.SupportedLanguages(ctx, target)
+	c.stats.addCall(0, err)
+	return langs, err
+}
+
+func (c *statsClient) TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	alts, err := gtrans.TranslateAlternatives(ctx, c.Translator, input, target, opts, n)
+	c.stats.addCall(countChars([]string{input}), err)
+	return alts, err
+}
+
+func (c *statsClient) Unwrap() gtrans.Translator {
+	return c.Translator
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// lastSourceFile returns the path of the file which records the detected
+// source language of the last translation for -swap.
+func lastSourceFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last_source"), nil
+}
+
+// lastSource records the detected source language of the last translation
+// in a run for -swap. It's saved once at the end of the run so that
+// concurrent translations don't race on the file. Methods of nil
+// *lastSource do nothing.
+type lastSource struct {
+	mu  sync.Mutex
+	tag language.Tag
+}
+
+// record records the detected source language of translations. Nothing is
+// recorded if the source language is not detected.
+func (l *lastSource) record(translations []gtrans.Translation) {
+	if l == nil {
+		return
+	}
+	for _, t := range translations {
+		if t.Source == language.Und {
+			continue
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.tag = t.Source
+		return
+	}
+}
+
+// save writes the recorded source language to lastSourceFile.
+func (l *lastSource) save() error {
+	if l == nil || l.tag == language.Und {
+		return nil
+	}
+	path, err := lastSourceFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(l.tag.String()+"\n"), 0600)
+}
+
+// loadLastSource returns the detected source language of the last
+// translation.
+func loadLastSource() (string, error) {
+	path, err := lastSourceFile()
+	if err != nil {
+		return "", err
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", errors.New("-swap requires a previous translation whose source language is detected")
+	}
+	if err != nil {
+		return "", err
+	}
+	lang := strings.TrimSpace(string(b))
+	if _, err := language.Parse(lang); err != nil {
+		return "", err
+	}
+	return lang, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// maxSuggestions is the max number of suggested language codes for an
+// unsupported language.
+const maxSuggestions = 3
+
+// validateLangs returns an error if target, second or source language is not
+// supported by client. The error suggests similar supported language codes.
+func validateLangs(ctx context.Context, client gtrans.Translator, opts options, targetLangs []string) error {
+	langs, err := gtrans.SupportedLanguages(ctx, "en", gtrans.WithClient(client))
+	if err != nil {
+		return fmt.Errorf("failed to get supported languages for -validate: %w", err)
+	}
+	codes := make([]string, len(langs))
+	for i, l := range langs {
+		codes[i] = l.Tag.String()
+	}
+	check := func(kind, lang string) error {
+		if lang == "" {
+			return nil
+		}
+		for _, code := range codes {
+			if strings.EqualFold(code, lang) {
+				return nil
+			}
+		}
+		msg := fmt.Sprintf("%s language %q is not supported by -backend %s", kind, lang, opts.backend)
+		if s := suggestLangs(lang, codes); len(s) > 0 {
+			msg += fmt.Sprintf(". Did you mean %s?", strings.Join(s, " or "))
+		}
+		return configErrorf("%s", msg)
+	}
+	if !opts.detect {
+		for _, lang := range targetLangs {
+			if err := check("target", lang); err != nil {
+				return err
+			}
+		}
+		if len(targetLangs) == 1 {
+			if err := check("second", opts.secondLang); err != nil {
+				return err
+			}
+		}
+	}
+	return check("source", opts.sourceLang)
+}
+
+// suggestLangs returns codes similar to lang: codes of the same base
+// language (e.g. zh-CN for zh) and codes within an edit distance of one
+// (e.g. ja for jp).
+func suggestLangs(lang string, codes []string) []string {
+	lang = strings.ToLower(lang)
+	base := strings.SplitN(lang, "-", 2)[0]
+	type candidate struct {
+		code string
+		dist int
+	}
+	var cs []candidate
+	for _, code := range codes {
+		c := strings.ToLower(code)
+		if d := editDistance(lang, c); d <= 1 {
+			cs = append(cs, candidate{code, d})
+		} else if strings.SplitN(c, "-", 2)[0] == base {
+			cs = append(cs, candidate{code, d})
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].dist != cs[j].dist {
+			return cs[i].dist < cs[j].dist
+		}
+		return cs[i].code < cs[j].code
+	})
+	var suggestions []string
+	seen := make(map[string]bool)
+	for _, c := range cs {
+		if seen[c.code] || len(suggestions) == maxSuggestions {
+			continue
+		}
+		seen[c.code] = true
+		suggestions = append(suggestions, c.code)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
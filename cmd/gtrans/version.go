@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// Build information. Release builds stamp them with -ldflags. e.g.
+//
+//	go build -ldflags "-X main.version=v1.0.0 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version = "devel"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+func writeVersion(w io.Writer) {
+	fmt.Fprintf(w, "gtrans %s (commit: %s, built at: %s)\n", buildVersion(), commit, date)
+}
+
+// buildVersion returns the version of gtrans.
+func buildVersion() string {
+	if version == "devel" {
+		// Use module version for `go install github.com/haya14busa/gtrans/cmd/gtrans@<version>`.
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+	}
+	return version
+}
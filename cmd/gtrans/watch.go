@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+
+	"github.com/haya14busa/gtrans"
+)
+
+// runWatch polls the clipboard every -watch-interval and translates its text
+// whenever it changes until ctx is canceled. Text is translated after it
+// stays the same for one interval so that rapid changes are translated only
+// once. The text in the clipboard at start is not translated. -no-newline is
+// ignored.
+func runWatch(ctx context.Context, w io.Writer, client gtrans.Translator, opts options, targetLangs []string) error {
+	if clipboard.Unsupported {
+		return &configError{err: errors.New("-watch: clipboard is not supported on this platform")}
+	}
+	opts.noNewline = false
+	last, _ := clipboard.ReadAll()
+	pending := last
+	ticker := time.NewTicker(opts.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Interrupted by user. It's the normal way to quit.
+			return nil
+		case <-ticker.C:
+		}
+		text, err := clipboard.ReadAll()
+		if err != nil {
+			warnf(opts, "failed to read clipboard: %v", err)
+			continue
+		}
+		if text != pending {
+			// Wait until the text becomes stable.
+			pending = text
+			continue
+		}
+		if text == last {
+			continue
+		}
+		last = text
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if err := translateText(ctx, w, client, opts, targetLangs, text); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			opts.log.Errorf("%v", err)
+			continue
+		}
+		if opts.copy {
+			// Don't translate the translation written by -copy.
+			last, _ = clipboard.ReadAll()
+			pending = last
+		}
+	}
+}
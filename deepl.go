@@ -0,0 +1,159 @@
+package gtrans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// DeepL API endpoints. API keys of DeepL API Free end with ":fx".
+//
+// https://www.deepl.com/docs-api
+const (
+	deeplEndpoint     = "https://api.deepl.com/v2"
+	deeplFreeEndpoint = "https://api-free.deepl.com/v2"
+)
+
+// deeplClient is a Translator using DeepL API.
+type deeplClient struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewDeepLClient returns a Translator using DeepL API authenticated with
+// apiKey.
+//
+// DeepL API doesn't have an API to detect language, so DetectLanguage
+// translates inputs to detect their language, which consumes the character
+// quota. It doesn't report confidence either, so Confidence of detections
+// is always 1.
+func NewDeepLClient(apiKey string) (Translator, error) {
+	if apiKey == "" {
+		return nil, errors.New("DEEPL_API_KEY is not set")
+	}
+	endpoint := deeplEndpoint
+	if strings.HasSuffix(apiKey, ":fx") {
+		endpoint = deeplFreeEndpoint
+	}
+	return &deeplClient{apiKey: apiKey, endpoint: endpoint, httpClient: newHTTPClient()}, nil
+}
+
+type deeplTranslateRequest struct {
+	Text        []string `json:"text"`
+	TargetLang  string   `json:"target_lang"`
+	SourceLang  string   `json:"source_lang,omitempty"`
+	TagHandling string   `json:"tag_handling,omitempty"`
+}
+
+type deeplTranslateResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+func (c *deeplClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	req := &deeplTranslateRequest{Text: inputs, TargetLang: deeplTargetLang(target)}
+	if opts != nil {
+		if opts.Source != language.Und {
+			base, _ := opts.Source.Base()
+			req.SourceLang = strings.ToUpper(base.String())
+		}
+		if opts.Format == translate.HTML {
+			req.TagHandling = "html"
+		}
+	}
+	var resp deeplTranslateResponse
+	if err := c.do(ctx, http.MethodPost, "/translate", req, &resp); err != nil {
+		return nil, err
+	}
+	translations := make([]translate.Translation, len(resp.Translations))
+	for i, t := range resp.Translations {
+		translations[i] = translate.Translation{Text: t.Text, Source: parseDeepLLang(t.DetectedSourceLanguage)}
+	}
+	return translations, nil
+}
+
+func (c *deeplClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	translations, err := c.Translate(ctx, inputs, language.AmericanEnglish, nil)
+	if err != nil {
+		return nil, err
+	}
+	detections := make([][]translate.Detection, len(translations))
+	for i, t := range translations {
+		if t.Source != language.Und {
+			detections[i] = []translate.Detection{{Language: t.Source, Confidence: 1, IsReliable: true}}
+		}
+	}
+	return detections, nil
+}
+
+func (c *deeplClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	// DeepL only returns language names in English.
+	var resp []struct {
+		Language string `json:"language"`
+		Name     string `json:"name"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/languages?type=target", nil, &resp); err != nil {
+		return nil, err
+	}
+	langs := make([]translate.Language, len(resp))
+	for i, l := range resp {
+		langs[i] = translate.Language{Name: l.Name, Tag: parseDeepLLang(l.Language)}
+	}
+	return langs, nil
+}
+
+func (c *deeplClient) Close() error {
+	return nil
+}
+
+func (c *deeplClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	header := http.Header{"Authorization": {"DeepL-Auth-Key " + c.apiKey}}
+	if err := doJSON(ctx, c.httpClient, method, c.endpoint+path, header, body, out); err != nil {
+		return fmt.Errorf("deepl: %w", err)
+	}
+	return nil
+}
+
+// deeplTargetLang returns DeepL target language code of tag. DeepL requires
+// region for English and Portuguese (e.g. EN-US, PT-BR) and script for
+// Traditional Chinese.
+func deeplTargetLang(tag language.Tag) string {
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	switch base.String() {
+	case "en":
+		if region.String() == "GB" {
+			return "EN-GB"
+		}
+		return "EN-US"
+	case "pt":
+		if region.String() == "PT" {
+			return "PT-PT"
+		}
+		return "PT-BR"
+	case "zh":
+		if script, _ := tag.Script(); script.String() == "Hant" {
+			return "ZH-HANT"
+		}
+		return "ZH-HANS"
+	}
+	return strings.ToUpper(base.String())
+}
+
+// parseDeepLLang parses DeepL language code (e.g. EN, EN-US). It returns
+// language.Und if it's invalid.
+func parseDeepLLang(code string) language.Tag {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
@@ -0,0 +1,50 @@
+package gtrans
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultDetectionSample is the recommended number of characters sent to
+// detect the language of input. It's enough to identify the language of
+// most texts.
+const DefaultDetectionSample = 1000
+
+// WithDetectionSample makes functions send only the first n characters of
+// each input to detect its language, which saves the character quota for
+// large inputs. Whole inputs are still translated. Inputs are sent as they
+// are by default or if n is 0.
+func WithDetectionSample(n int) Option {
+	return func(c *config) { c.detectSample = n }
+}
+
+// detectionSamples returns the first n characters of each input. Inputs are
+// cut at whitespace if possible not to send a partial word.
+func detectionSamples(inputs []string, n int) []string {
+	if n <= 0 {
+		return inputs
+	}
+	samples := make([]string, len(inputs))
+	for i, input := range inputs {
+		samples[i] = detectionSample(input, n)
+	}
+	return samples
+}
+
+func detectionSample(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		// Short input is sent as it is.
+		return text
+	}
+	end := 0
+	for i := 0; i < n; i++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	sample := text[:end]
+	if j := strings.LastIndexFunc(sample, unicode.IsSpace); j > len(sample)/2 {
+		sample = sample[:j]
+	}
+	return sample
+}
@@ -0,0 +1,115 @@
+package gtrans
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MaxFetchSize is the max size of a page fetched by FetchText.
+const MaxFetchSize = 2 << 20
+
+// FetchText fetches the page at url and returns its visible text. Tags,
+// scripts and styles of HTML are stripped. Other content types such as
+// text/plain are returned as they are. It returns an error if the page is
+// larger than MaxFetchSize.
+func FetchText(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := newHTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxFetchSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(b) > MaxFetchSize {
+		return "", fmt.Errorf("failed to fetch %s: page is larger than %d bytes", url, MaxFetchSize)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "":
+		return HTMLText(bytes.NewReader(b))
+	}
+	return string(b), nil
+}
+
+// invisibleElements are HTML elements whose content is not visible text.
+var invisibleElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true,
+	"template": true, "svg": true, "iframe": true,
+}
+
+// blockElements are HTML elements which break lines.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"td": true, "th": true, "tr": true, "ul": true,
+}
+
+// HTMLText returns visible text of HTML read from r. Text of block elements
+// such as <p> and <li> is separated by newlines.
+func HTMLText(r io.Reader) (string, error) {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		if s := strings.Join(strings.Fields(line.String()), " "); s != "" {
+			lines = append(lines, s)
+		}
+		line.Reset()
+	}
+	skip := 0 // depth of invisible elements.
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return "", z.Err()
+			}
+			flush()
+			return strings.Join(lines, "\n"), nil
+		case html.TextToken:
+			if skip == 0 {
+				line.Write(z.Text())
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if invisibleElements[string(name)] {
+				skip++
+			} else if blockElements[string(name)] {
+				flush()
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if invisibleElements[string(name)] {
+				if skip > 0 {
+					skip--
+				}
+			} else if blockElements[string(name)] {
+				flush()
+			}
+		case html.SelfClosingTagToken:
+			if name, _ := z.TagName(); blockElements[string(name)] {
+				flush()
+			}
+		}
+	}
+}
@@ -1,183 +1,453 @@
-package main
+// Package gtrans provides functions to translate text using Google Translate.
+package gtrans
 
 import (
 	"context"
-	"errors"
-	"flag"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
-	"net/url"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
 
 	"cloud.google.com/go/translate"
-	openbrowser "github.com/haya14busa/go-openbrowser"
-	"golang.org/x/oauth2"
 	"golang.org/x/text/language"
 	"google.golang.org/api/googleapi/transport"
 	"google.golang.org/api/option"
 )
 
-const usageMessage = "" +
-	`Usage:	gtrans [flags] [input text]
-	gtrans translates input text specified by argument or STDIN using Google Translate.
-	Source language will be automatically detected.
+// Translation represents a translated text of an input.
+type Translation struct {
+	// Input is the original text.
+	Input string
+	// Text is the translated text.
+	Text string
+	// Source is the source language. It's language.Und if unknown.
+	Source language.Tag
+	// Confidence is the confidence of the detected source language. It's 0
+	// if the source language is not detected by DetectLanguage.
+	Confidence float64
+	// Target is the target language actually used for the translation.
+	Target language.Tag
+	// Alternatives are alternative translations with WithAlternatives.
+	Alternatives []string
+}
 
-	export GOOGLE_TRANSLATE_API_KEY=<Your Google Translate API Key>
+// Translator is the interface of Google Translate client used by this
+// package. *translate.Client implements it.
+type Translator interface {
+	Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error)
+	DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error)
+	SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error)
+	Close() error
+}
 
-	[optional]
-	export GOOGLE_TRANSLATE_LANG=<default target language (e.g. en, ja, ...)>
-	export GOOGLE_TRANSLATE_SECOND_LANG=<second language (e.g. en, ja, ...)>
+var _ Translator = (*translate.Client)(nil)
 
-	If you set both GOOGLE_TRANSLATE_LANG and GOOGLE_TRANSLATE_SECOND_LANG,
-	gtrans automatically switches target langage.
+// NewClient returns a Google Translate client authenticated with apiKey. If
+// apiKey is empty, it uses the service account credentials file specified by
+// $GOOGLE_APPLICATION_CREDENTIALS or Application Default Credentials.
+// The client respects HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables. opts are passed to translate.NewClient.
+func NewClient(ctx context.Context, apiKey string, opts ...option.ClientOption) (Translator, error) {
+	if apiKey != "" {
+		opts = append([]option.ClientOption{option.WithHTTPClient(apiKeyHTTPClient(apiKey))}, opts...)
+		return translate.NewClient(ctx, opts...)
+	}
+	if file := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); file != "" {
+		opts = append(opts, option.WithCredentialsFile(file))
+	}
+	if UserAgent != "" {
+		opts = append([]option.ClientOption{option.WithUserAgent(UserAgent)}, opts...)
+	}
+	client, err := translate.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GOOGLE_TRANSLATE_API_KEY is not set and Google Cloud credentials are not available: %v", err)
+	}
+	return client, nil
+}
 
-	Example:
-		$ gtrans "Golang is awesome"
-		Golangは素晴らしいです
-		$ gtrans "Golangは素晴らしいです"
-		Golang is great
-		$ gtrans "Golangは素晴らしいです" | gtrans | gtrans | gtrans ...
-`
+// Option configures Translate, Detect and other functions in this package.
+type Option func(*config)
 
-var (
-	targetLang    string
-	doOpenBrowser bool
-)
+type config struct {
+	client     Translator
+	apiKey     string
+	sourceLang string
+	secondLang string
+	chunkSize  int
+	detect     bool
+	format     translate.Format
+	model      string
+	endpoint   string
+	preserve   []*regexp.Regexp
+	keepSpan   *regexp.Regexp
+	minConf    float64
+	// alternatives is the number of alternative translations.
+	alternatives int
+	skipSame     bool
+	// detectSample is the max number of characters of each input sent for
+	// detection. 0 means no limit.
+	detectSample int
+}
 
-func init() {
-	flag.StringVar(&targetLang, "to", "", "target language")
-	flag.BoolVar(&doOpenBrowser, "open", false, "open Google Translate in browser instead of writing translated result to STDOUT")
+func newConfig(opts []Option) *config {
+	c := &config{apiKey: os.Getenv("GOOGLE_TRANSLATE_API_KEY")}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
 }
 
-func usage() {
-	fmt.Fprintln(os.Stderr, usageMessage)
-	fmt.Fprintln(os.Stderr, "Flags:")
-	flag.PrintDefaults()
-	os.Exit(2)
+// WithAPIKey sets Google Translate API key. $GOOGLE_TRANSLATE_API_KEY is used
+// by default.
+func WithAPIKey(key string) Option {
+	return func(c *config) { c.apiKey = key }
 }
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
-	if err := Main(os.Stdin, os.Stdout, targetLang, doOpenBrowser); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
+// WithClient sets a client to use. The client is not closed by functions in
+// this package. By default, a new client is created and closed on each call.
+func WithClient(client Translator) Option {
+	return func(c *config) { c.client = client }
 }
 
-func Main(r io.Reader, w io.Writer, targetLang string, doOpenBrowser bool) error {
-	if targetLang == "" {
-		var err error
-		targetLang, err = detectTargetLang()
-		if err != nil {
-			return err
-		}
-	}
+// WithSource sets the source language. Source language is automatically
+// detected by default.
+func WithSource(lang string) Option {
+	return func(c *config) { c.sourceLang = lang }
+}
 
-	text := strings.Join(flag.Args(), " ")
-	if text == "" {
-		b, err := ioutil.ReadAll(r)
-		if err != nil {
-			return err
-		}
-		text = string(b)
-	}
+// WithSecondLang sets the second language. If the source language is the
+// same as the target language, text will be translated into the second
+// language instead.
+func WithSecondLang(lang string) Option {
+	return func(c *config) { c.secondLang = lang }
+}
 
-	if doOpenBrowser {
-		return openGoogleTranslate(w, targetLang, text)
-	}
-	return runTranslation(w, targetLang, text)
+// WithFormat sets the format of input, translate.Text or translate.HTML.
+// With translate.HTML, HTML tags are preserved and only text is translated.
+// Default is translate.Text.
+func WithFormat(format translate.Format) Option {
+	return func(c *config) { c.format = format }
+}
+
+// WithEndpoint sets the endpoint of Google Translate API such as a regional
+// endpoint (e.g. https://translation.googleapis.com/language/translate/).
+// It's ignored if WithClient is given.
+func WithEndpoint(endpoint string) Option {
+	return func(c *config) { c.endpoint = endpoint }
+}
+
+// WithModel sets the translation model, "nmt" (Neural Machine Translation)
+// or "base" (Phrase-Based Machine Translation). The API chooses the model by
+// default.
+func WithModel(model string) Option {
+	return func(c *config) { c.model = model }
+}
+
+// WithMinConfidence makes functions switch target language to the second
+// language only if confidence of the detected source language is at least
+// confidence. Target language is switched regardless of confidence by
+// default.
+func WithMinConfidence(confidence float64) Option {
+	return func(c *config) { c.minConf = confidence }
+}
+
+// WithDetection makes TranslateAll detect the source language by
+// DetectLanguage to report its confidence even if it's not necessary.
+func WithDetection() Option {
+	return func(c *config) { c.detect = true }
+}
+
+// WithSkipSame makes TranslateAll return inputs already in the target
+// language as they are without translating them. The language of each input
+// is detected unless the source language is given.
+func WithSkipSame() Option {
+	return func(c *config) { c.skipSame = true }
 }
 
-// https://translate.google.com/#auto/{lang}/{input}
-func openGoogleTranslate(w io.Writer, targetLang, text string) error {
-	u := fmt.Sprintf("https://translate.google.com/#auto/%s/%s", targetLang, url.QueryEscape(text))
-	return openbrowser.Start(u)
+// WithChunkSize makes functions split long input into chunks of at most size
+// characters to respect the API limit. Input is not split by default.
+func WithChunkSize(size int) Option {
+	return func(c *config) { c.chunkSize = size }
 }
 
-func runTranslation(w io.Writer, targetLang, text string) error {
-	ctx := context.Background()
-	apiKey := os.Getenv("GOOGLE_TRANSLATE_API_KEY")
-	if apiKey == "" {
-		return errors.New("GOOGLE_TRANSLATE_API_KEY is not set")
+// Translate translates text into targetLang.
+func Translate(ctx context.Context, text, targetLang string, opts ...Option) (string, error) {
+	translations, err := TranslateAll(ctx, []string{text}, targetLang, opts...)
+	if err != nil {
+		return "", err
 	}
+	return translations[0].Text, nil
+}
 
-	client, err := translate.NewClient(ctx, option.WithAPIKey(apiKey))
+// TranslateAll translates inputs into targetLang in one request. All inputs
+// are translated into the same language.
+func TranslateAll(ctx context.Context, inputs []string, targetLang string, opts ...Option) ([]Translation, error) {
+	cfg := newConfig(opts)
+	client, closeClient, err := cfg.openClient(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer closeClient()
+
+	texts := inputs
+	var m *masker
+	var tokens [][]string
+	if len(cfg.preserve) > 0 || cfg.keepSpan != nil {
+		m = newMasker(cfg.preserve, cfg.keepSpan)
+		texts = make([]string, len(inputs))
+		tokens = make([][]string, len(inputs))
+		for i, input := range inputs {
+			texts[i], tokens[i] = m.mask(input)
+		}
 	}
-	defer client.Close()
 
-	if sec := os.Getenv("GOOGLE_TRANSLATE_SECOND_LANG"); sec != "" {
-		detectionsList, err := client.DetectLanguage(ctx, []string{text})
+	opt := &translate.Options{Format: cfg.format, Model: cfg.model}
+	var detection translate.Detection
+	if cfg.sourceLang != "" {
+		// Source language is known. No need to spend an extra API call for
+		// detection.
+		opt.Source, err = parseLang(cfg.sourceLang)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		targetLang = SwitchTargetLang(opt.Source.String(), targetLang, cfg.secondLang)
+	} else if cfg.secondLang != "" || cfg.detect {
+		// Detect the language of whole inputs to choose one target language.
+		detectionsList, err := client.DetectLanguage(ctx, detectionSamples([]string{strings.Join(texts, "\n")}, cfg.detectSample))
+		if err != nil {
+			return nil, err
+		}
+		// Use the most likely detection of the joined input.
+		if len(detectionsList) > 0 && len(detectionsList[0]) > 0 {
+			detection = detectionsList[0][0]
+			if detection.Confidence >= cfg.minConf {
+				targetLang = SwitchTargetLang(detection.Language.String(), targetLang, cfg.secondLang)
+			}
+		}
+	}
+	targetLangTag, err := parseLang(targetLang)
+	if err != nil {
+		return nil, err
+	}
+	var translations []translate.Translation
+	if cfg.skipSame {
+		translations, err = translateDifferent(ctx, client, texts, targetLangTag, opt, cfg.chunkSize, cfg.detectSample)
+	} else {
+		translations, err = translateChunked(ctx, client, texts, targetLangTag, opt, cfg.chunkSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Translation, len(translations))
+	for i, t := range translations {
+		if m != nil {
+			t.Text = m.unmask(t.Text, tokens[i])
+		}
+		results[i] = Translation{Input: inputs[i], Text: t.Text, Source: t.Source, Confidence: detection.Confidence, Target: targetLangTag}
+		if results[i].Source == language.Und {
+			results[i].Source = opt.Source
 		}
-		for _, detections := range detectionsList {
-			for _, detection := range detections {
-				if detection.Language.String() == targetLang {
-					targetLang = sec
+		if results[i].Source == language.Und {
+			results[i].Source = detection.Language
+		}
+	}
+	if cfg.alternatives > 0 && SupportsAlternatives(client) {
+		for i, text := range texts {
+			alts, err := TranslateAlternatives(ctx, client, text, targetLangTag, opt, cfg.alternatives)
+			if err != nil {
+				return nil, err
+			}
+			for j, alt := range alts {
+				if m != nil {
+					alts[j] = m.unmask(alt, tokens[i])
 				}
-				break
+			}
+			results[i].Alternatives = alts
+		}
+	}
+	return results, nil
+}
+
+// translateDifferent translates only inputs whose language is different
+// from target. Inputs in the target language are returned as they are with
+// the detected language. Only the first sample characters of inputs are
+// sent for detection if sample is positive.
+func translateDifferent(ctx context.Context, client Translator, inputs []string, target language.Tag, opts *translate.Options, size, sample int) ([]translate.Translation, error) {
+	translations := make([]translate.Translation, len(inputs))
+	if opts.Source != language.Und {
+		if sameLanguage(opts.Source.String(), target.String()) {
+			for i, input := range inputs {
+				translations[i] = translate.Translation{Text: input, Source: opts.Source}
+			}
+			return translations, nil
+		}
+		return translateChunked(ctx, client, inputs, target, opts, size)
+	}
+	detectionsList, err := client.DetectLanguage(ctx, detectionSamples(inputs, sample))
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	var indices []int
+	for i, input := range inputs {
+		if i < len(detectionsList) && len(detectionsList[i]) > 0 {
+			lang := detectionsList[i][0].Language
+			if sameLanguage(lang.String(), target.String()) {
+				translations[i] = translate.Translation{Text: input, Source: lang}
+				continue
 			}
 		}
+		texts = append(texts, input)
+		indices = append(indices, i)
+	}
+	if len(texts) == 0 {
+		return translations, nil
 	}
-	targetLangTag, err := language.Parse(targetLang)
+	ts, err := translateChunked(ctx, client, texts, target, opts, size)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for j, t := range ts {
+		translations[indices[j]] = t
+	}
+	return translations, nil
+}
+
+// SwitchTargetLang returns secondLang if sourceLang is the same language as
+// targetLang. Otherwise, it returns targetLang. Regional variants such as en
+// and en-US are the same language, while scripts such as zh-CN (Simplified
+// Chinese) and zh-TW (Traditional Chinese) are not.
+func SwitchTargetLang(sourceLang, targetLang, secondLang string) string {
+	if secondLang != "" && sameLanguage(sourceLang, targetLang) {
+		return secondLang
 	}
-	opt := &translate.Options{}
-	translations, err := client.Translate(ctx, []string{text}, targetLangTag, opt)
+	return targetLang
+}
+
+// sameLanguage reports whether language codes a and b are the same language.
+func sameLanguage(a, b string) bool {
+	ta, err := parseLang(a)
 	if err != nil {
-		return err
+		return a == b
 	}
-	for _, translation := range translations {
-		fmt.Fprintln(w, translation.Text)
+	tb, err := parseLang(b)
+	if err != nil {
+		return a == b
 	}
-	return nil
+	_, _, conf := language.NewMatcher([]language.Tag{tb}).Match(ta)
+	return conf >= language.High
 }
 
-func oauthClient(ctx context.Context, apiKey string) *http.Client {
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
-		Transport: &transport.APIKey{Key: apiKey},
-	})
-	oauthConfig := &oauth2.Config{}
-	token := &oauth2.Token{AccessToken: apiKey}
-	httpClient := oauthConfig.Client(ctx, token)
-	return httpClient
+// Detect detects the language of text.
+func Detect(ctx context.Context, text string, opts ...Option) (translate.Detection, error) {
+	detections, err := DetectAll(ctx, []string{text}, opts...)
+	if err != nil {
+		return translate.Detection{}, err
+	}
+	return detections[0], nil
 }
 
-func detectTargetLang() (string, error) {
-	if code := os.Getenv("GOOGLE_TRANSLATE_LANG"); code != "" {
-		return code, nil
+// DetectAll detects the language of each input in one request. Language of
+// the returned detection is language.Und if it cannot be detected.
+func DetectAll(ctx context.Context, inputs []string, opts ...Option) ([]translate.Detection, error) {
+	cfg := newConfig(opts)
+	client, closeClient, err := cfg.openClient(ctx)
+	if err != nil {
+		return nil, err
 	}
-	for _, env := range []string{"LANGUAGE", "LC_ALL", "LANG"} {
-		code := langCodeFromLocale(os.Getenv(env))
-		if code != "" {
-			return code, nil
+	defer closeClient()
+
+	detectionsList, err := client.DetectLanguage(ctx, detectionSamples(inputs, cfg.detectSample))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]translate.Detection, len(inputs))
+	for i, detections := range detectionsList {
+		if len(detections) > 0 {
+			results[i] = detections[0]
 		}
 	}
-	return "", errors.New("cannot detect language. Please export $LANG or $GOOGLE_TRANSLATE_LANG (e.g. en, ja)")
+	return results, nil
 }
 
-// https://en.wikipedia.org/wiki/Locale_(computer_software)
-func langCodeFromLocale(locale string) string {
-	if strings.HasPrefix(locale, "zh_CN") || strings.HasPrefix(locale, "zh_SG") {
-		return "zh-CN"
+// DetectCandidates detects the language of each input in one request and
+// returns all candidate languages of each input sorted by confidence in
+// descending order. Some backends return only one candidate.
+func DetectCandidates(ctx context.Context, inputs []string, opts ...Option) ([][]translate.Detection, error) {
+	cfg := newConfig(opts)
+	client, closeClient, err := cfg.openClient(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer closeClient()
 
-	// Regions using Chinese Traditional: Taiwan, Hong Kong
-	if strings.HasPrefix(locale, "zh_TW") || strings.HasPrefix(locale, "zh_HK") {
-		return "zh-TW"
+	detectionsList, err := client.DetectLanguage(ctx, detectionSamples(inputs, cfg.detectSample))
+	if err != nil {
+		return nil, err
+	}
+	results := make([][]translate.Detection, len(inputs))
+	for i, detections := range detectionsList {
+		if i >= len(results) {
+			break
+		}
+		results[i] = append([]translate.Detection(nil), detections...)
+		sort.SliceStable(results[i], func(a, b int) bool { return results[i][a].Confidence > results[i][b].Confidence })
 	}
+	return results, nil
+}
 
-	i := strings.Index(locale, "_")
-	if i == -1 {
-		return ""
+// SupportedLanguages returns languages supported by Google Translate. Language
+// names are written in targetLang.
+func SupportedLanguages(ctx context.Context, targetLang string, opts ...Option) ([]translate.Language, error) {
+	targetLangTag, err := parseLang(targetLang)
+	if err != nil {
+		return nil, err
 	}
+	client, closeClient, err := newConfig(opts).openClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeClient()
+	return client.SupportedLanguages(ctx, targetLangTag)
+}
+
+// openClient returns the client set by WithClient, or creates a new one. The
+// returned func closes the client if it's created by openClient.
+func (c *config) openClient(ctx context.Context) (Translator, func(), error) {
+	if c.client != nil {
+		return c.client, func() {}, nil
+	}
+	var opts []option.ClientOption
+	if c.endpoint != "" {
+		opts = append(opts, option.WithEndpoint(c.endpoint))
+	}
+	client, err := NewClient(ctx, c.apiKey, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, func() { client.Close() }, nil
+}
+
+// apiKeyHTTPClient returns an HTTP client which authenticates requests with
+// apiKey. It respects proxy environment variables and sets UserAgent.
+func apiKeyHTTPClient(apiKey string) *http.Client {
+	return &http.Client{
+		Transport: &transport.APIKey{Key: apiKey, Transport: &userAgentTransport{base: proxyTransport()}},
+	}
+}
+
+// newHTTPClient returns an HTTP client which respects proxy environment
+// variables and sets UserAgent.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: &userAgentTransport{base: proxyTransport()}}
+}
 
-	return locale[:i]
+// proxyTransport returns a transport which uses proxy configured by
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func proxyTransport() http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	return t
 }
@@ -0,0 +1,151 @@
+package gtrans
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+func TestSwitchTargetLang(t *testing.T) {
+	tests := []struct {
+		source, target, second string
+		want                   string
+	}{
+		{source: "en", target: "ja", second: "en", want: "ja"},
+		{source: "ja", target: "ja", second: "en", want: "en"},
+		{source: "ja", target: "en", second: "ja", want: "en"},
+		{source: "en", target: "en-US", second: "ja", want: "ja"},
+		{source: "en-GB", target: "en-US", second: "ja", want: "ja"},
+		{source: "zh", target: "zh-CN", second: "en", want: "en"},
+		{source: "zh-CN", target: "zh-TW", second: "en", want: "zh-TW"},
+		{source: "ja", target: "ja", second: "", want: "ja"},
+	}
+	for _, tt := range tests {
+		if got := SwitchTargetLang(tt.source, tt.target, tt.second); got != tt.want {
+			t.Errorf("SwitchTargetLang(%q, %q, %q) = %q, want %q", tt.source, tt.target, tt.second, got, tt.want)
+		}
+	}
+}
+
+// stubTranslator is a Translator which detects every input as detected and
+// translates it into "<target>:<input>".
+type stubTranslator struct {
+	detected language.Tag
+	err      error
+	// detectCalls is the number of calls of DetectLanguage.
+	detectCalls int
+}
+
+func (s *stubTranslator) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	ts := make([]translate.Translation, len(inputs))
+	for i, input := range inputs {
+		ts[i] = translate.Translation{Text: target.String() + ":" + input}
+		if opts == nil || opts.Source == language.Und {
+			ts[i].Source = s.detected
+		}
+	}
+	return ts, nil
+}
+
+func (s *stubTranslator) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	s.detectCalls++
+	ds := make([][]translate.Detection, len(inputs))
+	for i := range inputs {
+		ds[i] = []translate.Detection{{Language: s.detected, Confidence: 0.9}}
+	}
+	return ds, nil
+}
+
+func (s *stubTranslator) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	return nil, nil
+}
+
+func (s *stubTranslator) Close() error { return nil }
+
+func TestTranslateAll(t *testing.T) {
+	errTranslate := errors.New("quota exceeded")
+	tests := []struct {
+		name         string
+		detected     language.Tag
+		translateErr error
+		target       string
+		opts         []Option
+		want         []Translation
+		wantDetects  int
+		wantErr      error
+	}{
+		{
+			name:     "explicit source",
+			detected: language.Japanese,
+			target:   "ja",
+			opts:     []Option{WithSource("en"), WithSecondLang("en")},
+			want:     []Translation{{Input: "hello", Text: "ja:hello", Source: language.English, Target: language.Japanese}},
+		},
+		{
+			name:     "explicit source matching the target",
+			detected: language.English,
+			target:   "ja",
+			opts:     []Option{WithSource("ja"), WithSecondLang("en")},
+			want:     []Translation{{Input: "hello", Text: "en:hello", Source: language.Japanese, Target: language.English}},
+		},
+		{
+			name:        "detected source different from the target",
+			detected:    language.English,
+			target:      "ja",
+			opts:        []Option{WithSecondLang("en")},
+			want:        []Translation{{Input: "hello", Text: "ja:hello", Source: language.English, Confidence: 0.9, Target: language.Japanese}},
+			wantDetects: 1,
+		},
+		{
+			name:        "detected source matching the target",
+			detected:    language.Japanese,
+			target:      "ja",
+			opts:        []Option{WithSecondLang("en")},
+			want:        []Translation{{Input: "hello", Text: "en:hello", Source: language.Japanese, Confidence: 0.9, Target: language.English}},
+			wantDetects: 1,
+		},
+		{
+			name:        "low confidence detection",
+			detected:    language.Japanese,
+			target:      "ja",
+			opts:        []Option{WithSecondLang("en"), WithMinConfidence(0.95)},
+			want:        []Translation{{Input: "hello", Text: "ja:hello", Source: language.Japanese, Confidence: 0.9, Target: language.Japanese}},
+			wantDetects: 1,
+		},
+		{
+			name:         "translate error",
+			detected:     language.English,
+			translateErr: errTranslate,
+			target:       "ja",
+			wantErr:      errTranslate,
+		},
+	}
+	for _, tt := range tests {
+		client := &stubTranslator{detected: tt.detected, err: tt.translateErr}
+		opts := append([]Option{WithClient(client)}, tt.opts...)
+		got, err := TranslateAll(context.Background(), []string{"hello"}, tt.target, opts...)
+		if err != tt.wantErr {
+			t.Errorf("%s: TranslateAll() error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if client.detectCalls != tt.wantDetects {
+			t.Errorf("%s: DetectLanguage was called %d times, want %d", tt.name, client.detectCalls, tt.wantDetects)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: TranslateAll() = %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			g, w := got[i], tt.want[i]
+			if g.Input != w.Input || g.Text != w.Text || g.Source != w.Source || g.Confidence != w.Confidence || g.Target != w.Target {
+				t.Errorf("%s: TranslateAll()[%d] = %+v, want %+v", tt.name, i, g, w)
+			}
+		}
+	}
+}
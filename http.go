@@ -0,0 +1,93 @@
+package gtrans
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize is the max size of response body kept in errors.
+const maxErrorBodySize = 4 << 10
+
+// UserAgent is the User-Agent of requests to translation backends and
+// fetched URLs. It's prepended to the User-Agent of client libraries if
+// any. Requests keep their User-Agent if it's empty.
+var UserAgent = "gtrans"
+
+// userAgentTransport is a transport which sets UserAgent to requests.
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if UserAgent == "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTrip must not modify the request.
+	req = req.Clone(req.Context())
+	ua := UserAgent
+	if orig := req.Header.Get("User-Agent"); orig != "" {
+		ua += " " + orig
+	}
+	req.Header.Set("User-Agent", ua)
+	return t.base.RoundTrip(req)
+}
+
+// APIError is an error response of HTTP APIs of translation backends other
+// than Google Translate.
+type APIError struct {
+	// Code is the HTTP status code.
+	Code int
+	// Body is the response body.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("HTTP %d %s", e.Code, http.StatusText(e.Code))
+	if body := strings.TrimSpace(e.Body); body != "" {
+		msg += ": " + body
+	}
+	return msg
+}
+
+// doJSON sends a request with JSON body and decodes JSON response into out.
+// body is not sent if it's nil. header is added to the request.
+// Responses with non-2xx status are returned as *APIError.
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, body, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return &APIError{Code: resp.StatusCode, Body: string(b)}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
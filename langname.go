@@ -0,0 +1,123 @@
+package gtrans
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// langNameAliases maps common language names which display names don't
+// cover to language codes.
+var langNameAliases = map[string]string{
+	"chinese":            "zh-CN",
+	"simplifiedchinese":  "zh-CN",
+	"traditionalchinese": "zh-TW",
+	"norwegian":          "no",
+	"farsi":              "fa",
+	"kurdish":            "ku",
+	"burmese":            "my",
+	"sorani":             "ckb",
+	"tagalog":            "tl",
+}
+
+// langCodeAliases maps deprecated language codes and country codes often
+// typed as language codes to language codes. Country codes which are valid
+// language codes by themselves such as "kr" (Kanuri) and "se" (Northern
+// Sami) are not mapped.
+var langCodeAliases = map[string]string{
+	// Deprecated ISO 639-1 codes. language.Parse accepts them but backends
+	// expect the current codes.
+	"iw": "he",
+	"jw": "jv",
+	"in": "id",
+	"ji": "yi",
+	// Country codes, which language.Parse rejects.
+	"jp": "ja",
+	"cn": "zh-CN",
+	"gr": "el",
+	"ua": "uk",
+	"dk": "da",
+	"cz": "cs",
+}
+
+// NormalizeLangCode returns the language code of code if it's a deprecated
+// code or an alias such as "iw" for Hebrew and "jp" for Japanese. Case and
+// surrounding whitespace are ignored. Otherwise, it returns code without
+// surrounding whitespace.
+func NormalizeLangCode(code string) string {
+	code = strings.TrimSpace(code)
+	if c, ok := langCodeAliases[strings.ToLower(code)]; ok {
+		return c
+	}
+	return code
+}
+
+// parseLang parses language code which may be an alias of
+// NormalizeLangCode.
+func parseLang(code string) (language.Tag, error) {
+	return language.Parse(NormalizeLangCode(code))
+}
+
+// langNames maps normalized language names in English and in the languages
+// themselves to language codes.
+var langNames = func() map[string]string {
+	names := make(map[string]string)
+	for name, code := range langNameAliases {
+		names[name] = code
+	}
+	for _, l := range KnownLanguages {
+		code := l.Code
+		tag := language.MustParse(code)
+		for _, name := range []string{display.English.Tags().Name(tag), display.Self.Name(tag)} {
+			if n := normalizeLangName(name); n != "" {
+				if _, ok := names[n]; !ok {
+					names[n] = code
+				}
+			}
+		}
+	}
+	return names
+}()
+
+// normalizeLangName returns lower-cased name without non-letters so that
+// "Chinese (Taiwan)" matches "chinese-taiwan".
+func normalizeLangName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// LangCodeFromName returns the language code for a language name in
+// English or in the language itself such as "Japanese" and "日本語". Case
+// and non-letters are ignored. A prefix of at least three letters matches
+// if only one language has it (e.g. "jap"). It returns "" if no language
+// matches.
+func LangCodeFromName(name string) string {
+	n := normalizeLangName(name)
+	if n == "" {
+		return ""
+	}
+	if code, ok := langNames[n]; ok {
+		return code
+	}
+	if len([]rune(n)) < 3 {
+		return ""
+	}
+	match := ""
+	for name, code := range langNames {
+		if !strings.HasPrefix(name, n) {
+			continue
+		}
+		if match != "" && match != code {
+			// Ambiguous.
+			return ""
+		}
+		match = code
+	}
+	return match
+}
@@ -0,0 +1,29 @@
+package gtrans
+
+import "testing"
+
+func TestNormalizeLangCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "iw", want: "he"},
+		{code: "jw", want: "jv"},
+		{code: "in", want: "id"},
+		{code: "ji", want: "yi"},
+		{code: "jp", want: "ja"},
+		{code: "cn", want: "zh-CN"},
+		{code: "JP", want: "ja"},
+		{code: "Iw", want: "he"},
+		{code: " cn\n", want: "zh-CN"},
+		{code: "ja", want: "ja"},
+		{code: "zh-TW", want: "zh-TW"},
+		{code: "kr", want: "kr"},
+		{code: " en ", want: "en"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLangCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeLangCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package gtrans
+
+// KnownLanguage is a language supported by Google Translate.
+type KnownLanguage struct {
+	// Code is the language code used by Google Translate such as zh-CN.
+	Code string
+	// Name is the English name of the language.
+	Name string
+}
+
+// KnownLanguages are the languages supported by Google Translate sorted by
+// code. They are bundled to look up languages without API requests. Use
+// SupportedLanguages for the languages actually supported by a backend.
+//
+// https://cloud.google.com/translate/docs/languages
+var KnownLanguages = []KnownLanguage{
+	{Code: "af", Name: "Afrikaans"},
+	{Code: "ak", Name: "Akan"},
+	{Code: "am", Name: "Amharic"},
+	{Code: "ar", Name: "Arabic"},
+	{Code: "as", Name: "Assamese"},
+	{Code: "ay", Name: "Aymara"},
+	{Code: "az", Name: "Azerbaijani"},
+	{Code: "be", Name: "Belarusian"},
+	{Code: "bg", Name: "Bulgarian"},
+	{Code: "bho", Name: "Bhojpuri"},
+	{Code: "bm", Name: "Bambara"},
+	{Code: "bn", Name: "Bengali"},
+	{Code: "bs", Name: "Bosnian"},
+	{Code: "ca", Name: "Catalan"},
+	{Code: "ceb", Name: "Cebuano"},
+	{Code: "ckb", Name: "Kurdish (Sorani)"},
+	{Code: "co", Name: "Corsican"},
+	{Code: "cs", Name: "Czech"},
+	{Code: "cy", Name: "Welsh"},
+	{Code: "da", Name: "Danish"},
+	{Code: "de", Name: "German"},
+	{Code: "doi", Name: "Dogri"},
+	{Code: "dv", Name: "Dhivehi"},
+	{Code: "ee", Name: "Ewe"},
+	{Code: "el", Name: "Greek"},
+	{Code: "en", Name: "English"},
+	{Code: "eo", Name: "Esperanto"},
+	{Code: "es", Name: "Spanish"},
+	{Code: "et", Name: "Estonian"},
+	{Code: "eu", Name: "Basque"},
+	{Code: "fa", Name: "Persian"},
+	{Code: "fi", Name: "Finnish"},
+	{Code: "fil", Name: "Filipino"},
+	{Code: "fr", Name: "French"},
+	{Code: "fy", Name: "Frisian"},
+	{Code: "ga", Name: "Irish"},
+	{Code: "gd", Name: "Scottish Gaelic"},
+	{Code: "gl", Name: "Galician"},
+	{Code: "gn", Name: "Guarani"},
+	{Code: "gom", Name: "Konkani"},
+	{Code: "gu", Name: "Gujarati"},
+	{Code: "ha", Name: "Hausa"},
+	{Code: "haw", Name: "Hawaiian"},
+	{Code: "he", Name: "Hebrew"},
+	{Code: "hi", Name: "Hindi"},
+	{Code: "hmn", Name: "Hmong"},
+	{Code: "hr", Name: "Croatian"},
+	{Code: "ht", Name: "Haitian Creole"},
+	{Code: "hu", Name: "Hungarian"},
+	{Code: "hy", Name: "Armenian"},
+	{Code: "id", Name: "Indonesian"},
+	{Code: "ig", Name: "Igbo"},
+	{Code: "ilo", Name: "Ilocano"},
+	{Code: "is", Name: "Icelandic"},
+	{Code: "it", Name: "Italian"},
+	{Code: "ja", Name: "Japanese"},
+	{Code: "jv", Name: "Javanese"},
+	{Code: "ka", Name: "Georgian"},
+	{Code: "kk", Name: "Kazakh"},
+	{Code: "km", Name: "Khmer"},
+	{Code: "kn", Name: "Kannada"},
+	{Code: "ko", Name: "Korean"},
+	{Code: "kri", Name: "Krio"},
+	{Code: "ku", Name: "Kurdish"},
+	{Code: "ky", Name: "Kyrgyz"},
+	{Code: "la", Name: "Latin"},
+	{Code: "lb", Name: "Luxembourgish"},
+	{Code: "lg", Name: "Luganda"},
+	{Code: "ln", Name: "Lingala"},
+	{Code: "lo", Name: "Lao"},
+	{Code: "lt", Name: "Lithuanian"},
+	{Code: "lus", Name: "Mizo"},
+	{Code: "lv", Name: "Latvian"},
+	{Code: "mai", Name: "Maithili"},
+	{Code: "mg", Name: "Malagasy"},
+	{Code: "mi", Name: "Maori"},
+	{Code: "mk", Name: "Macedonian"},
+	{Code: "ml", Name: "Malayalam"},
+	{Code: "mn", Name: "Mongolian"},
+	{Code: "mni-Mtei", Name: "Meiteilon (Manipuri)"},
+	{Code: "mr", Name: "Marathi"},
+	{Code: "ms", Name: "Malay"},
+	{Code: "mt", Name: "Maltese"},
+	{Code: "my", Name: "Burmese"},
+	{Code: "ne", Name: "Nepali"},
+	{Code: "nl", Name: "Dutch"},
+	{Code: "no", Name: "Norwegian"},
+	{Code: "nso", Name: "Sepedi"},
+	{Code: "ny", Name: "Chichewa"},
+	{Code: "om", Name: "Oromo"},
+	{Code: "or", Name: "Odia"},
+	{Code: "pa", Name: "Punjabi"},
+	{Code: "pl", Name: "Polish"},
+	{Code: "ps", Name: "Pashto"},
+	{Code: "pt", Name: "Portuguese"},
+	{Code: "pt-PT", Name: "Portuguese (Portugal)"},
+	{Code: "qu", Name: "Quechua"},
+	{Code: "ro", Name: "Romanian"},
+	{Code: "ru", Name: "Russian"},
+	{Code: "rw", Name: "Kinyarwanda"},
+	{Code: "sa", Name: "Sanskrit"},
+	{Code: "sd", Name: "Sindhi"},
+	{Code: "si", Name: "Sinhala"},
+	{Code: "sk", Name: "Slovak"},
+	{Code: "sl", Name: "Slovenian"},
+	{Code: "sm", Name: "Samoan"},
+	{Code: "sn", Name: "Shona"},
+	{Code: "so", Name: "Somali"},
+	{Code: "sq", Name: "Albanian"},
+	{Code: "sr", Name: "Serbian"},
+	{Code: "st", Name: "Sesotho"},
+	{Code: "su", Name: "Sundanese"},
+	{Code: "sv", Name: "Swedish"},
+	{Code: "sw", Name: "Swahili"},
+	{Code: "ta", Name: "Tamil"},
+	{Code: "te", Name: "Telugu"},
+	{Code: "tg", Name: "Tajik"},
+	{Code: "th", Name: "Thai"},
+	{Code: "ti", Name: "Tigrinya"},
+	{Code: "tk", Name: "Turkmen"},
+	{Code: "tl", Name: "Tagalog"},
+	{Code: "tr", Name: "Turkish"},
+	{Code: "ts", Name: "Tsonga"},
+	{Code: "tt", Name: "Tatar"},
+	{Code: "ug", Name: "Uyghur"},
+	{Code: "uk", Name: "Ukrainian"},
+	{Code: "ur", Name: "Urdu"},
+	{Code: "uz", Name: "Uzbek"},
+	{Code: "vi", Name: "Vietnamese"},
+	{Code: "xh", Name: "Xhosa"},
+	{Code: "yi", Name: "Yiddish"},
+	{Code: "yo", Name: "Yoruba"},
+	{Code: "zh-CN", Name: "Chinese (Simplified)"},
+	{Code: "zh-TW", Name: "Chinese (Traditional)"},
+	{Code: "zu", Name: "Zulu"},
+}
@@ -0,0 +1,181 @@
+package gtrans
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+)
+
+// DefaultLibreTranslateURL is the default base URL of LibreTranslate, which
+// is used by a locally running server.
+const DefaultLibreTranslateURL = "http://localhost:5000"
+
+// libreTranslateClient is a Translator using LibreTranslate API.
+//
+// https://libretranslate.com/docs/
+type libreTranslateClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewLibreTranslateClient returns a Translator using LibreTranslate API at
+// baseURL (e.g. http://localhost:5000). apiKey is optional and only required
+// by hosted instances.
+func NewLibreTranslateClient(baseURL, apiKey string) (Translator, error) {
+	if baseURL == "" {
+		baseURL = DefaultLibreTranslateURL
+	}
+	return &libreTranslateClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format,omitempty"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreDetection struct {
+	Language string `json:"language"`
+	// Confidence is in the range of 0 to 100.
+	Confidence float64 `json:"confidence"`
+}
+
+func (d libreDetection) detection() translate.Detection {
+	return translate.Detection{Language: parseLibreLang(d.Language), Confidence: d.Confidence / 100}
+}
+
+func (c *libreTranslateClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	req := &libreTranslateRequest{Q: inputs, Source: "auto", Target: libreLang(target), APIKey: c.apiKey}
+	if opts != nil {
+		if opts.Source != language.Und {
+			req.Source = libreLang(opts.Source)
+		}
+		if opts.Format != "" {
+			req.Format = string(opts.Format)
+		}
+	}
+	var resp struct {
+		TranslatedText   []string         `json:"translatedText"`
+		DetectedLanguage []libreDetection `json:"detectedLanguage"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/translate", req, &resp); err != nil {
+		return nil, err
+	}
+	translations := make([]translate.Translation, len(resp.TranslatedText))
+	for i, text := range resp.TranslatedText {
+		translations[i] = translate.Translation{Text: text}
+		if i < len(resp.DetectedLanguage) {
+			translations[i].Source = parseLibreLang(resp.DetectedLanguage[i].Language)
+		}
+	}
+	return translations, nil
+}
+
+func (c *libreTranslateClient) TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	// Alternatives are returned only for q of a string.
+	req := struct {
+		Q            string `json:"q"`
+		Source       string `json:"source"`
+		Target       string `json:"target"`
+		Format       string `json:"format,omitempty"`
+		Alternatives int    `json:"alternatives"`
+		APIKey       string `json:"api_key,omitempty"`
+	}{Q: input, Source: "auto", Target: libreLang(target), Alternatives: n, APIKey: c.apiKey}
+	if opts != nil {
+		if opts.Source != language.Und {
+			req.Source = libreLang(opts.Source)
+		}
+		if opts.Format != "" {
+			req.Format = string(opts.Format)
+		}
+	}
+	var resp struct {
+		Alternatives []string `json:"alternatives"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/translate", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Alternatives, nil
+}
+
+func (c *libreTranslateClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	// /detect accepts only one text per request.
+	detectionsList := make([][]translate.Detection, len(inputs))
+	for i, input := range inputs {
+		req := struct {
+			Q      string `json:"q"`
+			APIKey string `json:"api_key,omitempty"`
+		}{Q: input, APIKey: c.apiKey}
+		var resp []libreDetection
+		if err := c.do(ctx, http.MethodPost, "/detect", req, &resp); err != nil {
+			return nil, err
+		}
+		for _, d := range resp {
+			detectionsList[i] = append(detectionsList[i], d.detection())
+		}
+	}
+	return detectionsList, nil
+}
+
+func (c *libreTranslateClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	// LibreTranslate only returns language names in English.
+	var resp []struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/languages", nil, &resp); err != nil {
+		return nil, err
+	}
+	langs := make([]translate.Language, len(resp))
+	for i, l := range resp {
+		langs[i] = translate.Language{Name: l.Name, Tag: parseLibreLang(l.Code)}
+	}
+	return langs, nil
+}
+
+func (c *libreTranslateClient) Close() error {
+	return nil
+}
+
+func (c *libreTranslateClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	if err := doJSON(ctx, c.httpClient, method, c.baseURL+path, nil, body, out); err != nil {
+		return fmt.Errorf("libretranslate: %w", err)
+	}
+	return nil
+}
+
+// libreLang returns LibreTranslate language code of tag. LibreTranslate uses
+// zh for Simplified Chinese and zt for Traditional Chinese.
+func libreLang(tag language.Tag) string {
+	base, _ := tag.Base()
+	if base.String() == "zh" {
+		if script, _ := tag.Script(); script.String() == "Hant" {
+			return "zt"
+		}
+	}
+	return base.String()
+}
+
+// parseLibreLang parses LibreTranslate language code. It returns
+// language.Und if it's invalid.
+func parseLibreLang(code string) language.Tag {
+	if code == "zt" {
+		return language.TraditionalChinese
+	}
+	tag, err := language.Parse(code)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
@@ -0,0 +1,98 @@
+package gtrans
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// DefaultTargetLang returns the default target language. It uses
+// $GOOGLE_TRANSLATE_LANG if set, otherwise detects the language from locale
+// environment variables or the system locale on Windows.
+func DefaultTargetLang() (string, error) {
+	if code := os.Getenv("GOOGLE_TRANSLATE_LANG"); code != "" {
+		return code, nil
+	}
+	for _, env := range []string{"LANGUAGE", "LC_ALL", "LANG"} {
+		code := LangCodeFromLocale(os.Getenv(env))
+		if code != "" {
+			return code, nil
+		}
+	}
+	if code := LangCodeFromLocale(systemLocale()); code != "" {
+		return code, nil
+	}
+	return "", errors.New("cannot detect language. Please export $LANG or $GOOGLE_TRANSLATE_LANG (e.g. en, ja)")
+}
+
+// localeLangCodes maps locales to language codes for locales whose region
+// or script matters to Google Translate.
+var localeLangCodes = map[string]string{
+	"zh_CN": "zh-CN",
+	"zh_SG": "zh-CN",
+	// Regions using Chinese Traditional: Taiwan, Hong Kong, Macau
+	"zh_TW": "zh-TW",
+	"zh_HK": "zh-TW",
+	"zh_MO": "zh-TW",
+	// Script subtags used by Windows locale names (e.g. zh-Hant-TW).
+	"zh_Hans": "zh-CN",
+	"zh_Hant": "zh-TW",
+
+	"pt_BR": "pt",
+	"pt_PT": "pt-PT",
+}
+
+// localeLangAliases maps languages of locales to the ones Google Translate
+// supports.
+var localeLangAliases = map[string]string{
+	// Norwegian Bokmål and Nynorsk
+	"nb": "no",
+	"nn": "no",
+	// Filipino
+	"fil": "tl",
+}
+
+// LangCodeFromLocale returns a language code for Google Translate from locale
+// (e.g. ja_JP.UTF-8 -> ja). It returns empty string if locale doesn't
+// specify a language, such as C or POSIX.
+//
+// https://en.wikipedia.org/wiki/Locale_(computer_software)
+func LangCodeFromLocale(locale string) string {
+	// $LANGUAGE may be a list of locales (e.g. ja_JP:en_US).
+	if i := strings.IndexByte(locale, ':'); i != -1 {
+		locale = locale[:i]
+	}
+	// Strip codeset and modifier (e.g. ja_JP.UTF-8, de_DE@euro).
+	if i := strings.IndexAny(locale, ".@"); i != -1 {
+		locale = locale[:i]
+	}
+
+	parts := strings.Split(locale, "_")
+	for n := len(parts); n > 1; n-- {
+		if code, ok := localeLangCodes[strings.Join(parts[:n], "_")]; ok {
+			return code
+		}
+	}
+
+	lang := parts[0]
+	if !isLangSubtag(lang) {
+		return ""
+	}
+	if alias, ok := localeLangAliases[lang]; ok {
+		return alias
+	}
+	return lang
+}
+
+// isLangSubtag reports whether s looks like a ISO 639 language code.
+func isLangSubtag(s string) bool {
+	if len(s) < 2 || len(s) > 3 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
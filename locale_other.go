@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package gtrans
+
+// systemLocale returns the system locale. Locale environment variables are
+// used instead on non-Windows platforms.
+func systemLocale() string {
+	return ""
+}
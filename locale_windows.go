@@ -0,0 +1,28 @@
+//go:build windows
+// +build windows
+
+package gtrans
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var procGetUserDefaultLocaleName = syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// localeNameMaxLength is LOCALE_NAME_MAX_LENGTH.
+const localeNameMaxLength = 85
+
+// systemLocale returns the user default locale (e.g. ja_JP) by
+// GetUserDefaultLocaleName since locale environment variables are usually
+// not set on Windows.
+func systemLocale() string {
+	buf := make([]uint16, localeNameMaxLength)
+	r, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if r == 0 {
+		return ""
+	}
+	// Windows locale names are separated by "-" (e.g. ja-JP).
+	return strings.Replace(syscall.UTF16ToString(buf), "-", "_", -1)
+}
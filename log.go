@@ -0,0 +1,24 @@
+package gtrans
+
+// Logger receives diagnostic messages of the package. Infof receives
+// messages worth showing to operators such as retries, and Debugf receives
+// details such as cache hits and misses.
+type Logger interface {
+	Infof(format string, a ...interface{})
+	Debugf(format string, a ...interface{})
+}
+
+// Log is the Logger of the package. Messages are discarded if it's nil.
+var Log Logger
+
+func infof(format string, a ...interface{}) {
+	if Log != nil {
+		Log.Infof(format, a...)
+	}
+}
+
+func debugf(format string, a ...interface{}) {
+	if Log != nil {
+		Log.Debugf(format, a...)
+	}
+}
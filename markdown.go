@@ -0,0 +1,114 @@
+package gtrans
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// mdFence matches the opening line of a fenced code block.
+	mdFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// mdHTML matches the first line of an HTML block or a comment.
+	mdHTML = regexp.MustCompile(`^ {0,3}<(?:[A-Za-z][A-Za-z0-9-]*(?:[\s/>]|$)|/[A-Za-z]|!--|\?)`)
+	// mdIndentedCode matches a line of an indented code block.
+	mdIndentedCode = regexp.MustCompile(`^(?: {4}|\t)`)
+	// mdLine matches lines without prose such as thematic breaks, setext
+	// heading underlines, table delimiter rows and link reference
+	// definitions.
+	mdLine = regexp.MustCompile(`^ {0,3}(?:(?:[-*_][ \t]*){3,}|=+|\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?|\[[^\]]+\]:[ \t]*\S+.*)[ \t]*$`)
+	// mdMarker matches block markers at the beginning of a line: headings,
+	// blockquotes, list items and task list checkboxes.
+	mdMarker = regexp.MustCompile(`^[ \t]*(?:(?:#{1,6}|>|[-*+]|[0-9]{1,9}[.)])(?:[ \t]+|$)(?:\[[ xX]\][ \t]+)?)+`)
+	// mdTableRow matches a table row.
+	mdTableRow = regexp.MustCompile(`^[ \t]*\|`)
+)
+
+// MarkdownPreservePatterns are patterns of inline Markdown which must not be
+// translated: code spans, link destinations and titles, link references,
+// autolinks and inline HTML tags. Use them with WithPreserve to translate
+// segments of SplitMarkdown.
+var MarkdownPreservePatterns = []*regexp.Regexp{
+	regexp.MustCompile("``[^`\n](?:[^\n]*?[^`\n])?``|`[^`\n]+`"),
+	regexp.MustCompile(`\]\([^()\s]*(?:\([^()\s]*\)[^()\s]*)*(?:[ \t]+(?:"[^"]*"|'[^']*'))?\)`),
+	regexp.MustCompile(`\]\[[^\]]*\]`),
+	regexp.MustCompile(`<(?:[A-Za-z][A-Za-z0-9+.-]*:[^\s<>]*|[^\s<>@]+@[^\s<>]+)>`),
+	regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>`),
+}
+
+// SplitMarkdown splits a Markdown document into segments of prose such as
+// paragraphs, headings, list items and table rows. Fenced and indented code
+// blocks, HTML blocks, link reference definitions and block markers (e.g.
+// "# ", "- ", "> ") are kept in separators so that Join restores the
+// document structure. Inline code and links in segments are not handled;
+// use MarkdownPreservePatterns for them.
+func SplitMarkdown(text string) *Segments {
+	s := &Segments{}
+	sep := ""
+	// eol is the line ending of the last line of the current paragraph.
+	eol := ""
+	inPara := false
+	// table is true if the current paragraph is a table row, which doesn't
+	// continue to the next line.
+	table := false
+	var fence string
+	inHTML := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		content := strings.TrimRight(line, "\r\n")
+		end := line[len(content):]
+		blank := strings.TrimSpace(content) == ""
+		switch {
+		case fence != "":
+			// Closing fence must be the same kind and at least as long.
+			if f := mdFence.FindStringSubmatch(content); f != nil && f[1][0] == fence[0] && len(f[1]) >= len(fence) && strings.TrimSpace(content[len(f[0]):]) == "" {
+				fence = ""
+			}
+		case inHTML:
+			inHTML = !blank
+		case blank:
+		case mdFence.MatchString(content):
+			fence = mdFence.FindStringSubmatch(content)[1]
+		case mdHTML.MatchString(content):
+			inHTML = true
+		case mdLine.MatchString(content):
+		case !inPara && mdIndentedCode.MatchString(content) && !mdMarker.MatchString(content):
+		default:
+			marker := mdMarker.FindString(content)
+			row := mdTableRow.MatchString(content)
+			if inPara && !table && marker == "" && !row {
+				// Continuation of the paragraph.
+				s.Texts[len(s.Texts)-1] += eol + content
+				eol = end
+				continue
+			}
+			if inPara {
+				sep += eol
+			}
+			if strings.TrimSpace(content[len(marker):]) == "" {
+				// Empty list item or heading.
+				sep += line
+				inPara = false
+				continue
+			}
+			s.Texts = append(s.Texts, content[len(marker):])
+			s.seps = append(s.seps, sep+marker)
+			sep = ""
+			eol = end
+			inPara = true
+			table = row
+			continue
+		}
+		if inPara {
+			sep += eol
+			inPara = false
+		}
+		sep += line
+	}
+	if inPara {
+		sep += eol
+	}
+	s.seps = append(s.seps, sep)
+	return s
+}
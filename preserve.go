@@ -0,0 +1,87 @@
+package gtrans
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultPreservePatterns are patterns of tokens preserved by WithPreserve by
+// default: URLs, email addresses, printf-style format verbs (e.g. %s, %5.2f)
+// and placeholders (e.g. {0}, {name}, ${var}, {{var}}).
+var DefaultPreservePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`https?://[^\s<>"]*[^\s<>".,;:!?)\]']`),
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`%[-+# 0]*(?:[0-9]+|\*)?(?:\.(?:[0-9]+|\*))?[sdvqxXfFeEgGtbcoUTp]\b`),
+	regexp.MustCompile(`\$?\{\{?[^{}\s]*\}\}?`),
+}
+
+// placeholder matches placeholders of masked tokens. It allows spaces the
+// API may insert.
+var placeholder = regexp.MustCompile(`_\s*_\s*GT\s*([0-9]+)\s*_\s*_`)
+
+// WithPreserve makes functions keep tokens matching patterns as they are.
+// The tokens are replaced with placeholders before translation and restored
+// afterward. DefaultPreservePatterns is used if no patterns are given.
+func WithPreserve(patterns ...*regexp.Regexp) Option {
+	if len(patterns) == 0 {
+		patterns = DefaultPreservePatterns
+	}
+	return func(c *config) { c.preserve = patterns }
+}
+
+// WithKeepSpans makes functions keep text between open and close
+// delimiters (e.g. "<keep>" and "</keep>") as it is, such as product names.
+// The delimiters are removed from the translation. Spans can't be nested.
+func WithKeepSpans(open, close string) Option {
+	return func(c *config) {
+		c.keepSpan = regexp.MustCompile("(?s)" + regexp.QuoteMeta(open) + "(.*?)" + regexp.QuoteMeta(close))
+	}
+}
+
+// masker replaces tokens matching patterns with placeholders.
+type masker struct {
+	re *regexp.Regexp
+	// keep matches spans of WithKeepSpans, whose delimiters are removed
+	// on unmask. It can be nil.
+	keep *regexp.Regexp
+}
+
+func newMasker(patterns []*regexp.Regexp, keep *regexp.Regexp) *masker {
+	if keep != nil {
+		// Spans take precedence over tokens in them.
+		patterns = append([]*regexp.Regexp{keep}, patterns...)
+	}
+	alts := make([]string, len(patterns))
+	for i, p := range patterns {
+		alts[i] = "(?:" + p.String() + ")"
+	}
+	return &masker{re: regexp.MustCompile(strings.Join(alts, "|")), keep: keep}
+}
+
+// mask returns text with tokens replaced by placeholders and the tokens.
+func (m *masker) mask(text string) (string, []string) {
+	var tokens []string
+	masked := m.re.ReplaceAllStringFunc(text, func(token string) string {
+		if m.keep != nil {
+			if sm := m.keep.FindStringSubmatch(token); sm != nil && sm[0] == token {
+				token = sm[1]
+			}
+		}
+		tokens = append(tokens, token)
+		return fmt.Sprintf("__GT%d__", len(tokens)-1)
+	})
+	return masked, tokens
+}
+
+// unmask restores tokens masked by mask.
+func (m *masker) unmask(text string, tokens []string) string {
+	return placeholder.ReplaceAllStringFunc(text, func(s string) string {
+		i, err := strconv.Atoi(placeholder.FindStringSubmatch(s)[1])
+		if err != nil || i >= len(tokens) {
+			return s
+		}
+		return tokens[i]
+	})
+}
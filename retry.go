@@ -0,0 +1,156 @@
+package gtrans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/translate"
+	"golang.org/x/text/language"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryBaseDelay is the delay before the first retry. It's doubled on each
+// retry.
+const retryBaseDelay = 500 * time.Millisecond
+
+// RetryError is returned by the Translator of NewRetryClient when a request
+// still fails with a transient error after all retries.
+type RetryError struct {
+	// Retries is the number of retries made.
+	Retries int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (gave up after %d retries)", e.Err, e.Retries)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// retryClient is a Translator which retries requests on transient errors.
+type retryClient struct {
+	Translator
+	retries int
+}
+
+// NewRetryClient returns a Translator which retries requests of client up to
+// retries times with exponential backoff when they fail with transient
+// errors such as rate limit (429) or server errors (5xx). Requests are not
+// retried once ctx is done because its deadline or cancellation is not
+// transient. In that case, the error wraps ctx.Err() so that errors.Is
+// reports context.DeadlineExceeded or context.Canceled. A *RetryError is
+// returned if all retries fail.
+func NewRetryClient(client Translator, retries int) Translator {
+	return &retryClient{Translator: client, retries: retries}
+}
+
+func (c *retryClient) Translate(ctx context.Context, inputs []string, target language.Tag, opts *translate.Options) ([]translate.Translation, error) {
+	var translations []translate.Translation
+	err := c.retry(ctx, func() error {
+		var err error
+		translations, err = c.Translator.Translate(ctx, inputs, target, opts)
+		return err
+	})
+	return translations, err
+}
+
+func (c *retryClient) DetectLanguage(ctx context.Context, inputs []string) ([][]translate.Detection, error) {
+	var detections [][]translate.Detection
+	err := c.retry(ctx, func() error {
+		var err error
+		detections, err = c.Translator.DetectLanguage(ctx, inputs)
+		return err
+	})
+	return detections, err
+}
+
+func (c *retryClient) SupportedLanguages(ctx context.Context, target language.Tag) ([]translate.Language, error) {
+	var langs []translate.Language
+	err := c.retry(ctx, func() error {
+		var err error
+		langs, err = c.Translator.SupportedLanguages(ctx, target)
+		return err
+	})
+	return langs, err
+}
+
+func (c *retryClient) TranslateAlternatives(ctx context.Context, input string, target language.Tag, opts *translate.Options, n int) ([]string, error) {
+	var alts []string
+	err := c.retry(ctx, func() error {
+		var err error
+		alts, err = TranslateAlternatives(ctx, c.Translator, input, target, opts, n)
+		return err
+	})
+	return alts, err
+}
+
+func (c *retryClient) Unwrap() Translator {
+	return c.Translator
+}
+
+func (c *retryClient) retry(ctx context.Context, f func() error) error {
+	delay := retryBaseDelay
+	for i := 0; ; i++ {
+		err := f()
+		if err == nil || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+		if i >= c.retries {
+			if i == 0 {
+				return err
+			}
+			return &RetryError{Retries: i, Err: err}
+		}
+		// Add jitter to avoid retrying at the same time as other clients.
+		d := delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+		infof("retry %d/%d in %v: %v", i+1, c.retries, d.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w while waiting to retry: %v", ctx.Err(), err)
+		case <-time.After(d):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryable reports whether err is a transient error worth retrying.
+func isRetryable(err error) bool {
+	// Deadline of a request is set by the caller. Retrying it fails again.
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	code, ok := statusCode(err)
+	return ok && (code == http.StatusTooManyRequests || code >= 500)
+}
+
+// statusCode returns HTTP status code of API error err.
+func statusCode(err error) (int, bool) {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return gerr.Code, true
+	}
+	var aerr *APIError
+	if errors.As(err, &aerr) {
+		return aerr.Code, true
+	}
+	// gRPC errors of Cloud Translation Advanced API. Only transient errors
+	// are mapped.
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.ResourceExhausted:
+			return http.StatusTooManyRequests, true
+		case codes.Unavailable:
+			return http.StatusServiceUnavailable, true
+		case codes.Internal, codes.Unknown:
+			return http.StatusInternalServerError, true
+		}
+	}
+	return 0, false
+}
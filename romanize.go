@@ -0,0 +1,217 @@
+package gtrans
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Romanize returns romanized (transliterated into Latin script) text of s.
+// It supports Cyrillic, Greek, Hiragana, Katakana and Hangul, and removes
+// diacritics of other scripts. It doesn't handle Kanji (Han characters)
+// since their readings depend on words, so they are left as they are.
+//
+// It's a best-effort phonetic reading for people who can't read the script,
+// not a standard-compliant transliteration.
+func Romanize(s string) string {
+	var b strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case isKana(r):
+			j := i
+			for j < len(rs) && isKana(rs[j]) {
+				j++
+			}
+			b.WriteString(romanizeKana(rs[i:j]))
+			i = j - 1
+		case r >= hangulBase && r <= hangulLast:
+			b.WriteString(romanizeHangul(r))
+		default:
+			if rom, ok := romanizeCyrillic(r); ok {
+				b.WriteString(rom)
+				continue
+			}
+			b.WriteString(removeDiacritics(string(r)))
+		}
+	}
+	return b.String()
+}
+
+var diacriticsRemover = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// removeDiacritics removes diacritics of s and romanizes Greek letters.
+func removeDiacritics(s string) string {
+	s, _, err := transform.String(diacriticsRemover, s)
+	if err != nil {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if rom, ok := greekLatin[unicode.ToLower(r)]; ok {
+			if unicode.IsUpper(r) {
+				rom = capitalize(rom)
+			}
+			b.WriteString(rom)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// Ukrainian and Belarusian.
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "w",
+}
+
+func romanizeCyrillic(r rune) (string, bool) {
+	rom, ok := cyrillicLatin[unicode.ToLower(r)]
+	if ok && unicode.IsUpper(r) {
+		rom = capitalize(rom)
+	}
+	return rom, ok
+}
+
+var greekLatin = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Hangul syllables are composed of initial, medial and final jamo.
+// https://www.unicode.org/versions/latest/ch03.pdf (3.12 Conjoining Jamo Behavior)
+const (
+	hangulBase        = 0xAC00
+	hangulLast        = 0xD7A3
+	hangulMedialCount = 21
+	hangulFinalCount  = 28
+)
+
+// Revised Romanization of Korean without sound change rules.
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+func romanizeHangul(r rune) string {
+	i := int(r - hangulBase)
+	return hangulInitials[i/(hangulMedialCount*hangulFinalCount)] +
+		hangulMedials[i%(hangulMedialCount*hangulFinalCount)/hangulFinalCount] +
+		hangulFinals[i%hangulFinalCount]
+}
+
+func isKana(r rune) bool {
+	return (r >= 'ぁ' && r <= 'ゖ') || (r >= 'ァ' && r <= 'ヺ') || r == 'ー'
+}
+
+// kanaRomaji is Hepburn romanization of Hiragana. Katakana is converted to
+// Hiragana before lookup.
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゎ': "wa",
+	'ゕ': "ka", 'ゖ': "ke",
+}
+
+// romanizeKana romanizes a sequence of Hiragana and Katakana.
+func romanizeKana(kana []rune) string {
+	hira := make([]rune, len(kana))
+	for i, r := range kana {
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 'ァ' - 'ぁ'
+		}
+		hira[i] = r
+	}
+	var b strings.Builder
+	sokuon := false // small tsu doubles the next consonant.
+	for i := 0; i < len(hira); i++ {
+		switch hira[i] {
+		case 'っ':
+			sokuon = true
+			continue
+		case 'ー':
+			// Long vowel mark repeats the last vowel.
+			if s := b.String(); s != "" && strings.ContainsRune("aiueo", rune(s[len(s)-1])) {
+				b.WriteByte(s[len(s)-1])
+			}
+			continue
+		}
+		rom, ok := kanaRomaji[hira[i]]
+		if !ok {
+			b.WriteRune(kana[i])
+			continue
+		}
+		if i+1 < len(hira) {
+			if combined, ok := combineSmallKana(rom, hira[i+1]); ok {
+				rom = combined
+				i++
+			}
+		}
+		if sokuon {
+			if strings.HasPrefix(rom, "ch") {
+				b.WriteByte('t')
+			} else if !strings.ContainsRune("aiueon", rune(rom[0])) {
+				b.WriteByte(rom[0])
+			}
+			sokuon = false
+		}
+		b.WriteString(rom)
+	}
+	return b.String()
+}
+
+// combineSmallKana combines romaji of a kana with the following small kana
+// such as きゃ (kya) and ふぁ (fa). It returns false if they can't be
+// combined.
+func combineSmallKana(rom string, small rune) (string, bool) {
+	if len(rom) < 2 {
+		return "", false
+	}
+	stem := rom[:len(rom)-1]
+	switch small {
+	case 'ゃ', 'ゅ', 'ょ':
+		if !strings.HasSuffix(rom, "i") {
+			return "", false
+		}
+		y := map[rune]string{'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo"}[small]
+		if strings.HasSuffix(stem, "sh") || strings.HasSuffix(stem, "ch") || stem == "j" {
+			y = y[1:]
+		}
+		return stem + y, true
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		return stem + kanaRomaji[small], true
+	}
+	return "", false
+}
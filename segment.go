@@ -0,0 +1,101 @@
+package gtrans
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	lineSep      = regexp.MustCompile(`(?:\r?\n)+`)
+	paragraphSep = regexp.MustCompile(`\r?\n(?:[ \t]*\r?\n)+`)
+)
+
+// Segments represents text split into segments. Separators between segments
+// are kept so that the original structure can be restored by Join.
+type Segments struct {
+	// Texts are segments of text without separators.
+	Texts []string
+	// seps[0] is the text before Texts[0] and seps[i+1] is the separator
+	// after Texts[i].
+	seps []string
+}
+
+// SplitLines splits text into lines. Consecutive newlines are treated as one
+// separator.
+func SplitLines(text string) *Segments {
+	return split(text, lineSep)
+}
+
+// SplitParagraphs splits text into paragraphs separated by blank lines.
+func SplitParagraphs(text string) *Segments {
+	return split(text, paragraphSep)
+}
+
+func split(text string, sep *regexp.Regexp) *Segments {
+	s := &Segments{}
+	start := 0
+	prev := ""
+	for _, loc := range sep.FindAllStringIndex(text, -1) {
+		if loc[0] == 0 {
+			// Leading separator.
+			prev = text[:loc[1]]
+			start = loc[1]
+			continue
+		}
+		s.Texts = append(s.Texts, text[start:loc[0]])
+		s.seps = append(s.seps, prev)
+		prev = text[loc[0]:loc[1]]
+		start = loc[1]
+	}
+	if start < len(text) {
+		s.Texts = append(s.Texts, text[start:])
+		s.seps = append(s.seps, prev)
+		prev = ""
+	}
+	s.seps = append(s.seps, prev)
+	return s
+}
+
+// SplitSpace returns text as a segment without its leading and trailing
+// whitespace, which is kept in the separators.
+func SplitSpace(text string) *Segments {
+	s := &Segments{Texts: []string{text}, seps: []string{"", ""}}
+	s.TrimSpace()
+	return s
+}
+
+// TrimSpace moves leading and trailing whitespace of s.Texts into the
+// separators so that only the trimmed texts are translated and Join restores
+// the original whitespace such as indentation. Texts of only whitespace are
+// merged into the separators.
+func (s *Segments) TrimSpace() {
+	var texts, seps []string
+	sep := s.seps[0]
+	for i, t := range s.Texts {
+		core := strings.TrimLeftFunc(t, unicode.IsSpace)
+		lead := t[:len(t)-len(core)]
+		core = strings.TrimRightFunc(core, unicode.IsSpace)
+		if core == "" {
+			sep += t + s.seps[i+1]
+			continue
+		}
+		texts = append(texts, core)
+		seps = append(seps, sep+lead)
+		sep = t[len(lead)+len(core):] + s.seps[i+1]
+	}
+	s.Texts = texts
+	s.seps = append(seps, sep)
+}
+
+// Join joins texts, which correspond to s.Texts, with the original
+// separators.
+func (s *Segments) Join(texts []string) string {
+	var b strings.Builder
+	for i, t := range texts {
+		b.WriteString(s.seps[i])
+		b.WriteString(t)
+	}
+	b.WriteString(s.seps[len(texts)])
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package gtrans
+
+import "regexp"
+
+// srtHeader matches the index and timestamp lines of a SubRip (SRT) block.
+// e.g.
+//
+//	1
+//	00:00:01,000 --> 00:00:04,000
+var srtHeader = regexp.MustCompile(`^\x{FEFF}?[0-9]+[ \t]*\r?\n[0-9]+:[0-9]+:[0-9]+[,.][0-9]+[ \t]*-->[^\n]*\n`)
+
+// SplitSRT splits SubRip (SRT) subtitles into texts of subtitle blocks.
+// Indices and timestamps are kept in separators so that Join restores valid
+// SRT with the same timings. Text of a block may have multiple lines. Blocks
+// which are not well-formed are kept as they are.
+func SplitSRT(text string) *Segments {
+	blocks := SplitParagraphs(text)
+	s := &Segments{}
+	sep := ""
+	for i, block := range blocks.Texts {
+		sep += blocks.seps[i]
+		loc := srtHeader.FindStringIndex(block)
+		if loc == nil || loc[1] == len(block) {
+			sep += block
+			continue
+		}
+		s.Texts = append(s.Texts, block[loc[1]:])
+		s.seps = append(s.seps, sep+block[:loc[1]])
+		sep = ""
+	}
+	s.seps = append(s.seps, sep+blocks.seps[len(blocks.Texts)])
+	return s
+}